@@ -0,0 +1,245 @@
+package clock
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"sort"
+	"text/tabwriter"
+	"time"
+)
+
+// Actor 是 Simulator 上的一个逻辑参与者（例如：请求处理流水线中的一个环节），
+// 实现了 Clock 接口。
+//
+// Actor 的所有操作都直接由 Simulator 完成，
+// 只是会记录它在 Sleep 和等待 Timer 上花费的虚拟时间，
+// 模拟结束后，使用 Simulator.Report 查看，相当于虚拟延迟的 profiler。
+//
+// Timer 从创建或 Reset 开始，到触发，Stop 或者再次 Reset 为止，都算作等待。
+// AfterFunc，Ticker 和上下文的到期不算作等待。
+type Actor struct {
+	s    *Simulator
+	name string
+}
+
+// ActorStats 是一个 Actor 花费的虚拟时间
+type ActorStats struct {
+	Name string
+	// Sleeps 是 Sleep 的次数，SleepTime 是 Sleep 的总时长
+	Sleeps    int
+	SleepTime time.Duration
+	// Waits 是等待 Timer 的次数，WaitTime 是等待 Timer 的总时长
+	Waits    int
+	WaitTime time.Duration
+	// Max 是单次 Sleep 或等待的最长时长
+	Max time.Duration
+}
+
+// Total 返回 Sleep 和等待的总时长
+func (a ActorStats) Total() time.Duration {
+	return a.SleepTime + a.WaitTime
+}
+
+// Report 是所有 Actor 的统计，按照总时长由多到少排列
+type Report []ActorStats
+
+func (r Report) String() string {
+	var buf bytes.Buffer
+	w := tabwriter.NewWriter(&buf, 0, 4, 2, ' ', tabwriter.AlignRight)
+	fmt.Fprintln(w, "actor\tsleeps\tsleep\twaits\twait\tmax\ttotal\t")
+	for _, a := range r {
+		fmt.Fprintf(w, "%s\t%d\t%s\t%d\t%s\t%s\t%s\t\n",
+			a.Name, a.Sleeps, a.SleepTime, a.Waits, a.WaitTime, a.Max, a.Total())
+	}
+	w.Flush()
+	return buf.String()
+}
+
+// Actor 返回名为 name 的 *Actor
+func (s *Simulator) Actor(name string) *Actor {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.actorStats(name)
+	return &Actor{s: s, name: name}
+}
+
+// Report 返回所有 Actor 花费的虚拟时间
+func (s *Simulator) Report() Report {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	r := make(Report, 0, len(s.actors))
+	for _, a := range s.actors {
+		r = append(r, *a)
+	}
+	sort.Slice(r, func(i, j int) bool {
+		if r[i].Total() != r[j].Total() {
+			return r[i].Total() > r[j].Total()
+		}
+		return r[i].Name < r[j].Name
+	})
+	return r
+}
+
+// NOTICE: 务必在临界区内运行此方法
+func (s *Simulator) actorStats(name string) *ActorStats {
+	if s.actors == nil {
+		s.actors = make(map[string]*ActorStats)
+	}
+	a, ok := s.actors[name]
+	if !ok {
+		a = &ActorStats{Name: name}
+		s.actors[name] = a
+	}
+	return a
+}
+
+// WithActor 让 ctx 中的时钟，把虚拟时间记在 name 名下，
+// 之后 clock.Sleep(ctx, d) 等封装的等待时间，都会出现在 Simulator.Report 中。
+// ctx 中的时钟不是 *Simulator 或 *Actor 的话，直接返回 ctx。
+func WithActor(ctx context.Context, name string) context.Context {
+	switch c := Get(ctx).(type) {
+	case *Simulator:
+		return Set(ctx, c.Actor(name))
+	case *Actor:
+		return Set(ctx, c.s.Actor(name))
+	default:
+		return ctx
+	}
+}
+
+// Name 返回 Actor 的名称
+func (a *Actor) Name() string {
+	return a.name
+}
+
+// Simulator 返回 a 所在的 *Simulator
+func (a *Actor) Simulator() *Simulator {
+	return a.s
+}
+
+// track 记录 t 的等待时间，sleep 表示 t 用于 Sleep
+// NOTICE: 务必在 a.s 的临界区内运行此方法
+func (a *Actor) track(t *Timer, sleep bool) *Timer {
+	s := a.s
+	armed := s.now
+	record := func() {
+		d := s.now.Sub(armed)
+		stats := s.actorStats(a.name)
+		if sleep {
+			stats.Sleeps++
+			stats.SleepTime += d
+		} else {
+			stats.Waits++
+			stats.WaitTime += d
+		}
+		if d > stats.Max {
+			stats.Max = d
+		}
+	}
+	run := t.task.runFunc
+	t.task.runFunc = func(tk *task) *task {
+		record()
+		return run(tk)
+	}
+	t.Stop = func() bool {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		isActive := s.stopTask(t.task)
+		if isActive {
+			record()
+		}
+		return isActive
+	}
+	t.Reset = func(d time.Duration) bool {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		isActive := s.stopTask(t.task)
+		if isActive {
+			record()
+		}
+		armed = s.now
+		t.drain()
+		s.resetTask(t.task, d)
+		return isActive
+	}
+	return t
+}
+
+// Now returns the current wall time of the Simulator.
+func (a *Actor) Now() time.Time {
+	return a.s.Now()
+}
+
+// Since returns the time elapsed since t.
+func (a *Actor) Since(t time.Time) time.Duration {
+	return a.s.Since(t)
+}
+
+// Until returns the duration until t.
+func (a *Actor) Until(t time.Time) time.Duration {
+	return a.s.Until(t)
+}
+
+// Sleep pauses the current goroutine for at least the duration d,
+// and records d as sleep time of a.
+func (a *Actor) Sleep(d time.Duration) {
+	if d <= 0 {
+		return
+	}
+	s := a.s
+	s.mu.Lock()
+	t := a.track(s.newTimerFunc(s.now.Add(d), nil), true)
+	s.mu.Unlock()
+	<-t.C
+}
+
+// After waits for the duration to elapse and then sends the current time on
+// the returned channel.
+func (a *Actor) After(d time.Duration) <-chan time.Time {
+	return a.NewTimer(d).C
+}
+
+// NewTimer creates a new Timer that will send the current time on its channel
+// after at least duration d.
+func (a *Actor) NewTimer(d time.Duration) *Timer {
+	s := a.s
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return a.track(s.newTimerFunc(s.now.Add(d), nil), false)
+}
+
+// AfterFunc waits for the duration to elapse and then calls f in its own goroutine.
+// 回调不算作等待，所以不会被记录。
+func (a *Actor) AfterFunc(d time.Duration, f func()) *Timer {
+	return a.s.AfterFunc(d, f)
+}
+
+// NewTicker returns a new Ticker containing a channel that will send the
+// current time with a period specified by the duration d.
+func (a *Actor) NewTicker(d time.Duration) *Ticker {
+	return a.s.NewTicker(d)
+}
+
+// Tick is a convenience wrapper for NewTicker providing access to the ticking
+// channel only.
+func (a *Actor) Tick(d time.Duration) <-chan time.Time {
+	return a.s.Tick(d)
+}
+
+// ContextWithDeadline implements Clock.
+// 返回的上下文中的时钟是 a，所以后续的等待仍然记在 a 的名下。
+func (a *Actor) ContextWithDeadline(parent context.Context, deadline time.Time) (context.Context, context.CancelFunc) {
+	s := a.s
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.localContextWithDeadline(a, parent, deadline, s.monoOf(deadline))
+}
+
+// ContextWithTimeout implements Clock.
+func (a *Actor) ContextWithTimeout(parent context.Context, timeout time.Duration) (context.Context, context.CancelFunc) {
+	s := a.s
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.localContextWithDeadline(a, parent, s.wallNow().Add(timeout), s.now.Add(timeout))
+}
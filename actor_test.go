@@ -0,0 +1,152 @@
+package clock
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func Test_Actor(t *testing.T) {
+	Convey("Simulator 上的 Actor", t, func() {
+		now := time.Date(2020, 5, 20, 0, 0, 0, 0, time.UTC)
+		s := NewSimulator(now)
+		ctx := context.Background()
+		db := s.Actor("db")
+		So(db.Name(), ShouldEqual, "db")
+		So(db.Simulator(), ShouldEqual, s)
+		Convey("记录 Sleep 的时间", func() {
+			done := make(chan struct{})
+			go func() {
+				db.Sleep(3 * time.Second)
+				db.Sleep(time.Second)
+				close(done)
+			}()
+			So(s.BlockUntil(ctx, 1), ShouldBeNil)
+			s.Move()
+			So(s.BlockUntil(ctx, 1), ShouldBeNil)
+			s.Move()
+			<-done
+			r := s.Report()
+			So(len(r), ShouldEqual, 1)
+			So(r[0].Sleeps, ShouldEqual, 2)
+			So(r[0].SleepTime, ShouldEqual, 4*time.Second)
+			So(r[0].Max, ShouldEqual, 3*time.Second)
+			So(r[0].Total(), ShouldEqual, 4*time.Second)
+		})
+		Convey("Timer 触发时，记录等待的时间", func() {
+			t := db.NewTimer(5 * time.Second)
+			s.Add(5 * time.Second)
+			So(<-t.C, ShouldEqual, now.Add(5*time.Second))
+			So(s.Report()[0].Waits, ShouldEqual, 1)
+			So(s.Report()[0].WaitTime, ShouldEqual, 5*time.Second)
+			Convey("已经触发的 Timer 被 Stop 时，不再记录", func() {
+				So(t.Stop(), ShouldBeFalse)
+				So(s.Report()[0].Waits, ShouldEqual, 1)
+			})
+		})
+		Convey("Timer 被 Stop 时，记录已经等待的时间", func() {
+			t := db.NewTimer(10 * time.Second)
+			s.Add(4 * time.Second)
+			So(t.Stop(), ShouldBeTrue)
+			So(s.Report()[0].Waits, ShouldEqual, 1)
+			So(s.Report()[0].WaitTime, ShouldEqual, 4*time.Second)
+		})
+		Convey("Timer 被 Reset 时，重新开始计时", func() {
+			t := db.NewTimer(10 * time.Second)
+			s.Add(2 * time.Second)
+			So(t.Reset(time.Second), ShouldBeTrue)
+			s.Add(time.Second)
+			<-t.C
+			So(s.Report()[0].Waits, ShouldEqual, 2)
+			So(s.Report()[0].WaitTime, ShouldEqual, 3*time.Second)
+			So(s.Report()[0].Max, ShouldEqual, 2*time.Second)
+		})
+		Convey("AfterFunc 和 Ticker 不算作等待", func() {
+			db.AfterFunc(time.Second, func() {})
+			ticker := db.NewTicker(time.Second)
+			defer ticker.Stop()
+			So(db.Tick(time.Second), ShouldNotBeNil)
+			s.Add(3 * time.Second)
+			So(s.Report()[0].Total(), ShouldEqual, 0)
+		})
+		Convey("其他方法与 Simulator 一致", func() {
+			s.Add(time.Minute)
+			So(db.Now(), ShouldEqual, s.Now())
+			So(db.Since(now), ShouldEqual, time.Minute)
+			So(db.Until(now), ShouldEqual, -time.Minute)
+			after := db.After(time.Second)
+			s.Add(time.Second)
+			So(<-after, ShouldEqual, now.Add(time.Minute+time.Second))
+		})
+		Convey("上下文中的时钟是 Actor", func() {
+			c1, cancel1 := db.ContextWithTimeout(ctx, time.Second)
+			defer cancel1()
+			c2, cancel2 := db.ContextWithDeadline(ctx, now.Add(time.Hour))
+			defer cancel2()
+			So(Get(c1), ShouldEqual, db)
+			So(Get(c2), ShouldEqual, db)
+			s.Add(time.Second)
+			<-c1.Done()
+			So(c2.Err(), ShouldBeNil)
+		})
+		Convey("Report 按照总时长排列", func() {
+			api := s.Actor("api")
+			cache := s.Actor("cache")
+			t1 := api.NewTimer(2 * time.Second)
+			t2 := db.NewTimer(time.Second)
+			t3 := cache.NewTimer(2 * time.Second)
+			s.Add(2 * time.Second)
+			<-t1.C
+			<-t2.C
+			<-t3.C
+			r := s.Report()
+			So(r[0].Name, ShouldEqual, "api")
+			So(r[1].Name, ShouldEqual, "cache")
+			So(r[2].Name, ShouldEqual, "db")
+			lines := strings.Split(strings.TrimSpace(r.String()), "\n")
+			So(len(lines), ShouldEqual, 4)
+			So(lines[0], ShouldContainSubstring, "actor")
+			So(lines[1], ShouldContainSubstring, "api")
+		})
+	})
+}
+
+func Test_WithActor(t *testing.T) {
+	Convey("使用上下文标记 Actor", t, func() {
+		now := time.Date(2020, 5, 20, 0, 0, 0, 0, time.UTC)
+		s := NewSimulator(now)
+		ctx := Set(context.Background(), s)
+		Convey("上下文中是 *Simulator", func() {
+			actx := WithActor(ctx, "api")
+			a, ok := Get(actx).(*Actor)
+			So(ok, ShouldBeTrue)
+			So(a.Name(), ShouldEqual, "api")
+			done := make(chan struct{})
+			go func() {
+				Sleep(actx, time.Second)
+				<-After(actx, time.Second)
+				close(done)
+			}()
+			So(s.BlockUntil(context.Background(), 1), ShouldBeNil)
+			s.Move()
+			So(s.BlockUntil(context.Background(), 1), ShouldBeNil)
+			s.Move()
+			<-done
+			r := s.Report()
+			So(r[0].Sleeps, ShouldEqual, 1)
+			So(r[0].Waits, ShouldEqual, 1)
+			So(r[0].Total(), ShouldEqual, 2*time.Second)
+			Convey("可以重新标记", func() {
+				dctx := WithActor(actx, "db")
+				So(Get(dctx).(*Actor).Name(), ShouldEqual, "db")
+			})
+		})
+		Convey("上下文中是真实时钟时，不做改变", func() {
+			bg := context.Background()
+			So(WithActor(bg, "api") == bg, ShouldBeTrue)
+		})
+	})
+}
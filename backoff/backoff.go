@@ -0,0 +1,133 @@
+// Package backoff 提供了基于 clock 时间线的退避与重试功能。
+//
+// 所有的等待都通过 ctx 中的 Clock 完成，
+// 所以，在 ctx 中放入 *clock.Simulator 后，就可以精确地断言每一次重试的时间点。
+package backoff
+
+import (
+	"math"
+	"math/rand"
+	"sync"
+	"time"
+)
+
+// Stop 表示不再进行重试
+const Stop time.Duration = -1
+
+// Backoff 决定了每次失败后，需要等待多久才能再次尝试。
+type Backoff interface {
+	// Next 返回第 attempt 次失败后的等待时长，attempt 从 1 开始。
+	// 返回 Stop 表示不再重试。
+	Next(attempt int) time.Duration
+	// Reset 让 Backoff 回到初始状态
+	Reset()
+}
+
+// constant 每次都等待相同的时长
+type constant struct {
+	interval time.Duration
+}
+
+// Constant 返回每次都等待 interval 的 Backoff
+func Constant(interval time.Duration) Backoff {
+	return constant{interval: interval}
+}
+
+func (c constant) Next(int) time.Duration {
+	return c.interval
+}
+
+func (constant) Reset() {}
+
+// exponential 的等待时长按照 initial * multiplier^(attempt-1) 增长，
+// 但不会超过 max
+type exponential struct {
+	initial    time.Duration
+	max        time.Duration
+	multiplier float64
+}
+
+// Exponential 返回指数增长的 Backoff
+// multiplier < 1 时，按照 2 处理。
+// max <= 0 时，表示没有上限。
+func Exponential(initial, max time.Duration, multiplier float64) Backoff {
+	if multiplier < 1 {
+		multiplier = 2
+	}
+	return exponential{
+		initial:    initial,
+		max:        max,
+		multiplier: multiplier,
+	}
+}
+
+func (e exponential) Next(attempt int) time.Duration {
+	if attempt < 1 {
+		attempt = 1
+	}
+	d := float64(e.initial) * math.Pow(e.multiplier, float64(attempt-1))
+	// 防止溢出
+	if e.max > 0 && d > float64(e.max) {
+		return e.max
+	}
+	if d > math.MaxInt64 {
+		return time.Duration(math.MaxInt64)
+	}
+	return time.Duration(d)
+}
+
+func (exponential) Reset() {}
+
+// decorrelatedJitter 实现了 AWS 建议的 Decorrelated Jitter 算法
+//
+//	sleep = min(cap, random_between(base, sleep * 3))
+//
+// https://aws.amazon.com/blogs/architecture/exponential-backoff-and-jitter/
+type decorrelatedJitter struct {
+	sync.Mutex
+	base, cap time.Duration
+	last      time.Duration
+	rand      *rand.Rand
+}
+
+// DecorrelatedJitter 返回带有去相关抖动的 Backoff
+// r 为 nil 时，使用 math/rand 的全局随机源。
+// 需要可以复现的等待时长时（比如：测试），请传入固定种子的 r。
+//
+// 返回的 Backoff 会记录上一次的等待时长，所以不能被多个 Retry 共享。
+func DecorrelatedJitter(base, cap time.Duration, r *rand.Rand) Backoff {
+	return &decorrelatedJitter{
+		base: base,
+		cap:  cap,
+		last: base,
+		rand: r,
+	}
+}
+
+func (j *decorrelatedJitter) Next(int) time.Duration {
+	j.Lock()
+	defer j.Unlock()
+	upper := j.last * 3
+	if upper <= j.base {
+		upper = j.base + 1
+	}
+	d := j.base + time.Duration(j.int63n(int64(upper-j.base)))
+	if j.cap > 0 && d > j.cap {
+		d = j.cap
+	}
+	j.last = d
+	return d
+}
+
+func (j *decorrelatedJitter) int63n(n int64) int64 {
+	if j.rand == nil {
+		return rand.Int63n(n)
+	}
+	return j.rand.Int63n(n)
+}
+
+func (j *decorrelatedJitter) Reset() {
+	j.Lock()
+	j.last = j.base
+	j.Unlock()
+}
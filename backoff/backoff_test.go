@@ -0,0 +1,71 @@
+package backoff
+
+import (
+	"math/rand"
+	"testing"
+	"time"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func Test_Constant(t *testing.T) {
+	Convey("Constant 的等待时长", t, func() {
+		b := Constant(time.Second)
+		Convey("每次都一样", func() {
+			for i := 1; i < 5; i++ {
+				So(b.Next(i), ShouldEqual, time.Second)
+			}
+		})
+	})
+}
+
+func Test_Exponential(t *testing.T) {
+	Convey("Exponential 的等待时长", t, func() {
+		b := Exponential(time.Second, 10*time.Second, 2)
+		Convey("按照指数增长", func() {
+			So(b.Next(1), ShouldEqual, time.Second)
+			So(b.Next(2), ShouldEqual, 2*time.Second)
+			So(b.Next(3), ShouldEqual, 4*time.Second)
+			So(b.Next(4), ShouldEqual, 8*time.Second)
+		})
+		Convey("不会超过上限", func() {
+			So(b.Next(5), ShouldEqual, 10*time.Second)
+			So(b.Next(100), ShouldEqual, 10*time.Second)
+		})
+		Convey("multiplier 小于 1 时，按照 2 处理", func() {
+			b := Exponential(time.Second, 0, 0.5)
+			So(b.Next(3), ShouldEqual, 4*time.Second)
+		})
+		Convey("没有上限时，也不会溢出", func() {
+			b := Exponential(time.Second, 0, 2)
+			So(b.Next(1000), ShouldBeGreaterThan, 0)
+		})
+	})
+}
+
+func Test_DecorrelatedJitter(t *testing.T) {
+	Convey("DecorrelatedJitter 的等待时长", t, func() {
+		base, cap := time.Second, 30*time.Second
+		b := DecorrelatedJitter(base, cap, rand.New(rand.NewSource(1)))
+		Convey("总是在 [base, cap] 之间", func() {
+			for i := 1; i < 100; i++ {
+				d := b.Next(i)
+				So(d, ShouldBeGreaterThanOrEqualTo, base)
+				So(d, ShouldBeLessThanOrEqualTo, cap)
+			}
+		})
+		Convey("相同的种子，会得到相同的序列", func() {
+			other := DecorrelatedJitter(base, cap, rand.New(rand.NewSource(1)))
+			for i := 1; i < 10; i++ {
+				So(b.Next(i), ShouldEqual, other.Next(i))
+			}
+		})
+		Convey("Reset 后，会从 base 重新开始", func() {
+			first := DecorrelatedJitter(base, cap, rand.New(rand.NewSource(2)))
+			expected := first.Next(1)
+			second := DecorrelatedJitter(base, cap, rand.New(rand.NewSource(2)))
+			second.Reset()
+			So(second.Next(1), ShouldEqual, expected)
+		})
+	})
+}
@@ -0,0 +1,114 @@
+package backoff
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/jujili/clock"
+)
+
+// Operation 是需要重试的操作
+type Operation func(ctx context.Context) error
+
+// permanentError 包裹了不需要再重试的错误
+type permanentError struct {
+	err error
+}
+
+func (p *permanentError) Error() string { return p.err.Error() }
+
+func (p *permanentError) Unwrap() error { return p.err }
+
+// Permanent 包裹 err 后，Retry 会立即停止重试，并返回 err
+func Permanent(err error) error {
+	if err == nil {
+		return nil
+	}
+	return &permanentError{err: err}
+}
+
+// Policy 描述了重试的策略
+type Policy struct {
+	// Backoff 为 nil 时，不进行等待，立即重试
+	Backoff Backoff
+	// MaxAttempts 是最多尝试的次数，包括第一次。
+	// MaxAttempts <= 0 表示不限制次数
+	MaxAttempts int
+	// MaxElapsedTime 是从第一次尝试开始，允许花费的最长时间。
+	// 如果下一次等待结束的时刻超过了这个时长，就不再重试。
+	// MaxElapsedTime <= 0 表示不限制时间
+	MaxElapsedTime time.Duration
+	// Notify 不为 nil 时，会在每次等待前被调用
+	Notify func(err error, wait time.Duration)
+}
+
+// Retry 按照 p 的策略，反复执行 op，直到 op 返回 nil 为止。
+//
+// 所有的等待与计时，都使用 clock.Get(ctx) 的时间线。
+// ctx 结束的时候，Retry 会立刻返回 ctx.Err()。
+// 重试次数或时间耗尽的时候，Retry 返回 op 最后一次的错误。
+func (p Policy) Retry(ctx context.Context, op Operation) error {
+	if p.Backoff != nil {
+		p.Backoff.Reset()
+	}
+	start := clock.Now(ctx)
+	for attempt := 1; ; attempt++ {
+		err := op(ctx)
+		if err == nil {
+			return nil
+		}
+		var perm *permanentError
+		if errors.As(err, &perm) {
+			return perm.err
+		}
+		if p.MaxAttempts > 0 && attempt >= p.MaxAttempts {
+			return err
+		}
+		wait := p.next(attempt)
+		if wait == Stop {
+			return err
+		}
+		if p.MaxElapsedTime > 0 &&
+			clock.Since(ctx, start)+wait > p.MaxElapsedTime {
+			return err
+		}
+		if p.Notify != nil {
+			p.Notify(err, wait)
+		}
+		if sleepErr := sleep(ctx, wait); sleepErr != nil {
+			return sleepErr
+		}
+	}
+}
+
+func (p Policy) next(attempt int) time.Duration {
+	if p.Backoff == nil {
+		return 0
+	}
+	return p.Backoff.Next(attempt)
+}
+
+// Retry 是 Policy{Backoff: b}.Retry(ctx, op) 的简便写法
+func Retry(ctx context.Context, b Backoff, op Operation) error {
+	return Policy{Backoff: b}.Retry(ctx, op)
+}
+
+// sleep 在 ctx 的时间线上等待 d，
+// ctx 提前结束的话，返回 ctx.Err()
+func sleep(ctx context.Context, d time.Duration) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	if d <= 0 {
+		return nil
+	}
+	timer := clock.NewTimer(ctx, d)
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		timer.Stop()
+		return ctx.Err()
+	}
+}
@@ -0,0 +1,133 @@
+package backoff
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/jujili/clock"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+var errTest = errors.New("test error")
+
+// drive 不停地推动 s，直到 done 被关闭
+// NOTICE: time.Sleep 是为了让 Retry 有机会运行到等待的位置
+// 但也不是 100% 的保证
+func drive(s *clock.Simulator, done <-chan struct{}) {
+	for {
+		time.Sleep(time.Millisecond)
+		select {
+		case <-done:
+			return
+		default:
+			s.Move()
+		}
+	}
+}
+
+func Test_Retry(t *testing.T) {
+	Convey("在 Simulator 的时间线上重试", t, func() {
+		now := time.Date(2020, 5, 20, 0, 0, 0, 0, time.UTC)
+		s := clock.NewSimulator(now)
+		ctx := clock.Set(context.Background(), s)
+		var attempts []time.Time
+		failTimes := 3
+		op := func(ctx context.Context) error {
+			attempts = append(attempts, clock.Now(ctx))
+			if len(attempts) <= failTimes {
+				return errTest
+			}
+			return nil
+		}
+		run := func(p Policy) error {
+			var err error
+			done := make(chan struct{})
+			go func() {
+				err = p.Retry(ctx, op)
+				close(done)
+			}()
+			drive(s, done)
+			return err
+		}
+		Convey("Constant 的重试时间点是精确的", func() {
+			err := run(Policy{Backoff: Constant(time.Second)})
+			So(err, ShouldBeNil)
+			So(attempts, ShouldHaveLength, 4)
+			for i, a := range attempts {
+				So(a, ShouldEqual, now.Add(time.Duration(i)*time.Second))
+			}
+		})
+		Convey("Exponential 的重试时间点是精确的", func() {
+			err := run(Policy{Backoff: Exponential(time.Second, 0, 2)})
+			So(err, ShouldBeNil)
+			So(attempts[1], ShouldEqual, now.Add(1*time.Second))
+			So(attempts[2], ShouldEqual, now.Add(3*time.Second))
+			So(attempts[3], ShouldEqual, now.Add(7*time.Second))
+		})
+		Convey("超过 MaxAttempts 后，返回最后的错误", func() {
+			err := run(Policy{Backoff: Constant(time.Second), MaxAttempts: 2})
+			So(err, ShouldEqual, errTest)
+			So(attempts, ShouldHaveLength, 2)
+		})
+		Convey("超过 MaxElapsedTime 后，返回最后的错误", func() {
+			err := run(Policy{
+				Backoff:        Constant(time.Second),
+				MaxElapsedTime: 2500 * time.Millisecond,
+			})
+			So(err, ShouldEqual, errTest)
+			So(attempts, ShouldHaveLength, 3)
+			So(s.Now(), ShouldEqual, now.Add(2*time.Second))
+		})
+		Convey("Backoff 返回 Stop 时，不再重试", func() {
+			err := run(Policy{Backoff: Constant(Stop)})
+			So(err, ShouldEqual, errTest)
+			So(attempts, ShouldHaveLength, 1)
+		})
+		Convey("Permanent 的错误不会重试", func() {
+			permanent := errors.New("permanent")
+			count := 0
+			err := Policy{Backoff: Constant(time.Second)}.Retry(ctx, func(context.Context) error {
+				count++
+				return Permanent(permanent)
+			})
+			So(err, ShouldEqual, permanent)
+			So(count, ShouldEqual, 1)
+		})
+		Convey("Notify 会在每次等待前被调用", func() {
+			var errs []error
+			var waits []time.Duration
+			err := run(Policy{
+				Backoff: Exponential(time.Second, 0, 2),
+				Notify: func(err error, wait time.Duration) {
+					errs = append(errs, err)
+					waits = append(waits, wait)
+				},
+			})
+			So(err, ShouldBeNil)
+			So(errs, ShouldResemble, []error{errTest, errTest, errTest})
+			So(waits, ShouldResemble, []time.Duration{time.Second, 2 * time.Second, 4 * time.Second})
+		})
+		Convey("ContextWithTimeout 会中断等待", func() {
+			failTimes = 100
+			var cancel context.CancelFunc
+			ctx, cancel = clock.ContextWithTimeout(ctx, 2500*time.Millisecond)
+			defer cancel()
+			err := run(Policy{Backoff: Constant(time.Second)})
+			So(errors.Is(err, context.DeadlineExceeded), ShouldBeTrue)
+			So(attempts, ShouldHaveLength, 3)
+		})
+	})
+}
+
+func Test_Permanent(t *testing.T) {
+	Convey("Permanent(nil) 返回 nil", t, func() {
+		So(Permanent(nil), ShouldBeNil)
+	})
+	Convey("Permanent 的错误信息与原错误一致", t, func() {
+		err := Permanent(errTest)
+		So(err.Error(), ShouldEqual, errTest.Error())
+		So(errors.Is(err, errTest), ShouldBeTrue)
+	})
+}
@@ -0,0 +1,146 @@
+package clock
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"time"
+)
+
+// BudgetHeader 是跨进程传递剩余时间时，推荐使用的 HTTP header
+const BudgetHeader = "Clock-Budget"
+
+// ErrInvalidBudget 表示剩余时间的编码不正确
+var ErrInvalidBudget = errors.New("clock: 无效的剩余时间")
+
+// Budget 返回 ctx 的 deadline 之前，还剩下多少时间。
+// 时间来自 Get(ctx)，所以使用 *Simulator 时，返回的是虚拟的剩余时间。
+// 已经过期的话，返回 0；没有 deadline 的话，返回 false。
+func Budget(ctx context.Context) (time.Duration, bool) {
+	deadline, ok := ctx.Deadline()
+	if !ok {
+		return 0, false
+	}
+	d := Until(ctx, deadline)
+	if d < 0 {
+		d = 0
+	}
+	return d, true
+}
+
+// SubBudget 把 ctx 剩余时间的 fraction 分给下游的调用，
+// 返回的子上下文在 Now(ctx) + Budget(ctx) * fraction 到期。
+// ctx 没有 deadline 的话，子上下文也没有 deadline。
+// fraction 不在 (0, 1] 之内的话，会 panic。
+func SubBudget(ctx context.Context, fraction float64) (context.Context, context.CancelFunc) {
+	if !(fraction > 0 && fraction <= 1) {
+		panic("clock: SubBudget 的 fraction 必须在 (0, 1] 之内")
+	}
+	budget, ok := Budget(ctx)
+	if !ok {
+		return context.WithCancel(ctx)
+	}
+	return ContextWithTimeout(ctx, time.Duration(float64(budget)*fraction))
+}
+
+// ReserveBudget 为下游调用返回以后的处理，预留 reserve 的时间，
+// 返回的子上下文比 ctx 早 reserve 到期。
+// 剩余时间不足 reserve 的话，子上下文已经过期。
+// ctx 没有 deadline 的话，子上下文也没有 deadline。
+// reserve 为负数的话，会 panic。
+func ReserveBudget(ctx context.Context, reserve time.Duration) (context.Context, context.CancelFunc) {
+	if reserve < 0 {
+		panic("clock: ReserveBudget 的 reserve 不能为负数")
+	}
+	deadline, ok := ctx.Deadline()
+	if !ok {
+		return context.WithCancel(ctx)
+	}
+	return ContextWithDeadline(ctx, deadline.Add(-reserve))
+}
+
+// EncodeBudget 把 ctx 的剩余时间编码成 FormatBudget 的格式，
+// 用于跨进程传递，例如放在 BudgetHeader 中。
+// ctx 没有 deadline 的话，返回 false。
+func EncodeBudget(ctx context.Context) (string, bool) {
+	budget, ok := Budget(ctx)
+	if !ok {
+		return "", false
+	}
+	return FormatBudget(budget), true
+}
+
+// DecodeBudget 解析 EncodeBudget 的编码，
+// 返回的子上下文在 Now(ctx) 之后的剩余时间到期。
+// ctx 更早到期的话，子上下文沿用 ctx 的 deadline。
+func DecodeBudget(ctx context.Context, s string) (context.Context, context.CancelFunc, error) {
+	budget, err := ParseBudget(s)
+	if err != nil {
+		return nil, nil, err
+	}
+	ctx, cancel := ContextWithTimeout(ctx, budget)
+	return ctx, cancel, nil
+}
+
+// maxBudgetValue 是编码中数值的最大值，与 gRPC 一样，最多 8 位数字
+const maxBudgetValue = 1e8 - 1
+
+// budgetUnits 是编码中的单位，从小到大排列，与 gRPC 的 grpc-timeout 相同
+var budgetUnits = []struct {
+	unit byte
+	size time.Duration
+}{
+	{'n', time.Nanosecond},
+	{'u', time.Microsecond},
+	{'m', time.Millisecond},
+	{'S', time.Second},
+	{'M', time.Minute},
+	{'H', time.Hour},
+}
+
+// FormatBudget 把 d 编码成 gRPC 的 grpc-timeout 格式：
+// 不超过 8 位的十进制数字，加上一个单位，例如 "250m" 是 250 毫秒。
+// 使用能放下 d 的最小单位，放不下的部分被舍去，所以编码后的时间不会比 d 长。
+// d <= 0 的话，返回 "0n"。
+func FormatBudget(d time.Duration) string {
+	if d <= 0 {
+		return "0n"
+	}
+	for _, u := range budgetUnits {
+		if v := d / u.size; v <= maxBudgetValue {
+			return strconv.FormatInt(int64(v), 10) + string(u.unit)
+		}
+	}
+	// time.Duration 最多大约 256 万小时，不会执行到这里
+	return strconv.FormatInt(maxBudgetValue, 10) + "H"
+}
+
+// ParseBudget 解析 FormatBudget 的编码。
+// 超过 time.Duration 上限的话，返回 time.Duration 的最大值。
+func ParseBudget(s string) (time.Duration, error) {
+	if len(s) < 2 || len(s) > 9 {
+		return 0, fmt.Errorf("%w: %q", ErrInvalidBudget, s)
+	}
+	var size time.Duration
+	for _, u := range budgetUnits {
+		if s[len(s)-1] == u.unit {
+			size = u.size
+		}
+	}
+	if size == 0 {
+		return 0, fmt.Errorf("%w: %q 的单位不正确", ErrInvalidBudget, s)
+	}
+	digits := s[:len(s)-1]
+	for i := 0; i < len(digits); i++ {
+		if digits[i] < '0' || digits[i] > '9' {
+			return 0, fmt.Errorf("%w: %q 的数值不正确", ErrInvalidBudget, s)
+		}
+	}
+	v, _ := strconv.ParseInt(digits, 10, 64)
+	if v > math.MaxInt64/int64(size) {
+		return math.MaxInt64, nil
+	}
+	return time.Duration(v) * size, nil
+}
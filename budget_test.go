@@ -0,0 +1,148 @@
+package clock
+
+import (
+	"context"
+	"errors"
+	"math"
+	"testing"
+	"time"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func Test_Budget(t *testing.T) {
+	Convey("在 Simulator 上，剩余 10 秒的上下文", t, func() {
+		s := NewSimulator(time.Now())
+		ctx, cancel := s.ContextWithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+		Convey("Budget 返回虚拟的剩余时间", func() {
+			budget, ok := Budget(ctx)
+			So(ok, ShouldBeTrue)
+			So(budget, ShouldEqual, 10*time.Second)
+			s.Add(4 * time.Second)
+			budget, _ = Budget(ctx)
+			So(budget, ShouldEqual, 6*time.Second)
+		})
+		Convey("过期以后，Budget 返回 0", func() {
+			s.Add(time.Minute)
+			budget, ok := Budget(ctx)
+			So(ok, ShouldBeTrue)
+			So(budget, ShouldEqual, 0)
+		})
+		Convey("没有 deadline 的话，Budget 返回 false", func() {
+			_, ok := Budget(Set(context.Background(), s))
+			So(ok, ShouldBeFalse)
+		})
+		Convey("SubBudget 把剩余时间的一部分分给子上下文", func() {
+			sub, cancelSub := SubBudget(ctx, 0.3)
+			defer cancelSub()
+			budget, _ := Budget(sub)
+			So(budget, ShouldEqual, 3*time.Second)
+			s.Add(3 * time.Second)
+			<-sub.Done()
+			So(errors.Is(sub.Err(), context.DeadlineExceeded), ShouldBeTrue)
+			So(ctx.Err(), ShouldBeNil)
+			Convey("fraction 不在 (0, 1] 之内的话，会 panic", func() {
+				So(func() { SubBudget(ctx, 0) }, ShouldPanic)
+				So(func() { SubBudget(ctx, 1.5) }, ShouldPanic)
+				So(func() { SubBudget(ctx, math.NaN()) }, ShouldPanic)
+			})
+		})
+		Convey("ReserveBudget 为自己预留时间", func() {
+			sub, cancelSub := ReserveBudget(ctx, 2*time.Second)
+			defer cancelSub()
+			budget, _ := Budget(sub)
+			So(budget, ShouldEqual, 8*time.Second)
+			Convey("剩余时间不足的话，子上下文已经过期", func() {
+				sub, cancelSub := ReserveBudget(ctx, time.Minute)
+				defer cancelSub()
+				So(errors.Is(sub.Err(), context.DeadlineExceeded), ShouldBeTrue)
+			})
+			So(func() { ReserveBudget(ctx, -time.Second) }, ShouldPanic)
+		})
+		Convey("没有 deadline 的话，子上下文也没有 deadline", func() {
+			parent := Set(context.Background(), s)
+			sub, cancelSub := SubBudget(parent, 0.5)
+			_, ok := sub.Deadline()
+			So(ok, ShouldBeFalse)
+			cancelSub()
+			So(sub.Err(), ShouldEqual, context.Canceled)
+			sub, cancelSub = ReserveBudget(parent, time.Second)
+			defer cancelSub()
+			_, ok = sub.Deadline()
+			So(ok, ShouldBeFalse)
+		})
+		Convey("跨进程传递剩余时间", func() {
+			s.Add(1500 * time.Millisecond)
+			encoded, ok := EncodeBudget(ctx)
+			So(ok, ShouldBeTrue)
+			So(encoded, ShouldEqual, "8500000u")
+			_, ok = EncodeBudget(context.Background())
+			So(ok, ShouldBeFalse)
+			Convey("另一个进程中的 Simulator 解码以后，得到同样的剩余时间", func() {
+				remote := NewSimulator(time.Now().Add(time.Hour))
+				received, cancel, err := DecodeBudget(Set(context.Background(), remote), encoded)
+				So(err, ShouldBeNil)
+				defer cancel()
+				budget, _ := Budget(received)
+				So(budget, ShouldEqual, 8500*time.Millisecond)
+				remote.Add(8500 * time.Millisecond)
+				<-received.Done()
+				So(errors.Is(received.Err(), context.DeadlineExceeded), ShouldBeTrue)
+			})
+			Convey("编码错误的话，返回 ErrInvalidBudget", func() {
+				_, _, err := DecodeBudget(ctx, "1x")
+				So(errors.Is(err, ErrInvalidBudget), ShouldBeTrue)
+			})
+		})
+	})
+}
+
+func Test_FormatBudget(t *testing.T) {
+	Convey("使用能放下 d 的最小单位", t, func() {
+		So(FormatBudget(0), ShouldEqual, "0n")
+		So(FormatBudget(-time.Second), ShouldEqual, "0n")
+		So(FormatBudget(time.Nanosecond), ShouldEqual, "1n")
+		So(FormatBudget(99999999*time.Nanosecond), ShouldEqual, "99999999n")
+		So(FormatBudget(100*time.Millisecond), ShouldEqual, "100000u")
+		So(FormatBudget(time.Hour), ShouldEqual, "3600000m")
+		So(FormatBudget(30*24*time.Hour), ShouldEqual, "2592000S")
+		So(FormatBudget(math.MaxInt64), ShouldEqual, "2562047H")
+	})
+	Convey("放不下的部分被舍去，不会比 d 长", t, func() {
+		d := 100*time.Millisecond + 1
+		So(FormatBudget(d), ShouldEqual, "100000u")
+		parsed, err := ParseBudget(FormatBudget(d))
+		So(err, ShouldBeNil)
+		So(parsed, ShouldBeLessThanOrEqualTo, d)
+	})
+}
+
+func Test_ParseBudget(t *testing.T) {
+	Convey("解析 grpc-timeout 格式的剩余时间", t, func() {
+		for s, expected := range map[string]time.Duration{
+			"0n":        0,
+			"250m":      250 * time.Millisecond,
+			"3S":        3 * time.Second,
+			"2M":        2 * time.Minute,
+			"1H":        time.Hour,
+			"12u":       12 * time.Microsecond,
+			"99999999n": 99999999 * time.Nanosecond,
+		} {
+			d, err := ParseBudget(s)
+			So(err, ShouldBeNil)
+			So(d, ShouldEqual, expected)
+		}
+	})
+	Convey("格式错误时，返回 ErrInvalidBudget", t, func() {
+		for _, s := range []string{"", "m", "1", "99999999", "123456789m", "1.5S", "-1S", "10s", "+1S"} {
+			_, err := ParseBudget(s)
+			So(errors.Is(err, ErrInvalidBudget), ShouldBeTrue)
+		}
+	})
+	Convey("超过 time.Duration 上限的话，返回最大值", t, func() {
+		d, err := ParseBudget("99999999H")
+		So(err, ShouldBeNil)
+		So(d, ShouldEqual, time.Duration(math.MaxInt64))
+	})
+}
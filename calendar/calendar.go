@@ -0,0 +1,167 @@
+// Package calendar 提供基于工作日和节假日的时间计算。
+//
+// Calendar 描述了哪些日子是周末，哪些日子是节假日，以及在哪个时区判断日期。
+// 与时间线有关的函数都使用 clock.Clock，
+// 所以使用 *clock.Simulator 的时候，可以快进任意多个工作日。
+package calendar
+
+import (
+	"fmt"
+	"sync"
+	"time"
+)
+
+// Calendar 是工作日历，可以安全地并发使用
+type Calendar struct {
+	mu       sync.RWMutex
+	loc      *time.Location
+	weekend  [7]bool
+	holidays map[date]string
+}
+
+// date 是 Calendar 时区中的日期
+type date struct {
+	year  int
+	month time.Month
+	day   int
+}
+
+func dateOf(t time.Time) date {
+	y, m, d := t.Date()
+	return date{year: y, month: m, day: d}
+}
+
+// New 返回在 loc 时区判断日期的 *Calendar。
+// weekend 是每周休息的日子，省略的话，周六和周日休息。
+// weekend 中有不在 [time.Sunday, time.Saturday] 之内的值的话，会 panic。
+// loc 为 nil 时，使用 time.Local
+func New(loc *time.Location, weekend ...time.Weekday) *Calendar {
+	if loc == nil {
+		loc = time.Local
+	}
+	if len(weekend) == 0 {
+		weekend = []time.Weekday{time.Saturday, time.Sunday}
+	}
+	cal := &Calendar{
+		loc:      loc,
+		holidays: make(map[date]string),
+	}
+	for _, w := range weekend {
+		if w < time.Sunday || w > time.Saturday {
+			panic(fmt.Sprintf("calendar: 无效的星期 %d，必须在 [0, 6] 之内", w))
+		}
+		cal.weekend[w] = true
+	}
+	return cal
+}
+
+// Location 返回 cal 判断日期的时区
+func (cal *Calendar) Location() *time.Location {
+	return cal.loc
+}
+
+// AddHoliday 把 day 在 cal 的时区中所在的那一天，设置为名为 name 的节假日。
+// 同一天重复设置的话，使用最后一次的 name
+func (cal *Calendar) AddHoliday(day time.Time, name string) {
+	cal.mu.Lock()
+	defer cal.mu.Unlock()
+	cal.holidays[dateOf(day.In(cal.loc))] = name
+}
+
+// Holiday 返回 t 所在的那一天的节假日名称，不是节假日的话，返回 false
+func (cal *Calendar) Holiday(t time.Time) (string, bool) {
+	cal.mu.RLock()
+	defer cal.mu.RUnlock()
+	name, ok := cal.holidays[dateOf(t.In(cal.loc))]
+	return name, ok
+}
+
+// IsWeekend 返回 t 所在的那一天是否是周末
+func (cal *Calendar) IsWeekend(t time.Time) bool {
+	return cal.weekend[t.In(cal.loc).Weekday()]
+}
+
+// IsBusinessDay 返回 t 所在的那一天，是否既不是周末，也不是节假日
+func (cal *Calendar) IsBusinessDay(t time.Time) bool {
+	if cal.IsWeekend(t) {
+		return false
+	}
+	_, ok := cal.Holiday(t)
+	return !ok
+}
+
+// addDays 返回 t 在 cal 的时区中，n 天后的同一时刻。
+// 使用 time.Date 计算，所以跨过夏令时切换时，钟面时间不变
+func (cal *Calendar) addDays(t time.Time, n int) time.Time {
+	t = t.In(cal.loc)
+	y, m, d := t.Date()
+	h, mi, s := t.Clock()
+	return time.Date(y, m, d+n, h, mi, s, t.Nanosecond(), cal.loc)
+}
+
+// startOfDay 返回 t 在 cal 的时区中，所在那一天的零点
+func (cal *Calendar) startOfDay(t time.Time) time.Time {
+	y, m, d := t.In(cal.loc).Date()
+	return time.Date(y, m, d, 0, 0, 0, 0, cal.loc)
+}
+
+// maxSearchDays 是寻找工作日时，最多检查的天数，
+// 用于避免所有的日子都是周末时，陷入死循环
+const maxSearchDays = 366 * 10
+
+// AddBusinessDays 返回 t 之后第 n 个工作日的同一时刻，n 为负数时，往前数。
+// t 本身不计算在内，所以，周五或周六加 1 个工作日，都是下周一。
+// n 为 0 时，返回 t。
+// 一周的每一天都是周末的话，会 panic
+func (cal *Calendar) AddBusinessDays(t time.Time, n int) time.Time {
+	step := 1
+	if n < 0 {
+		step, n = -1, -n
+	}
+	for searched := 0; n > 0; searched++ {
+		if searched > maxSearchDays {
+			panic("calendar: 没有工作日")
+		}
+		t = cal.addDays(t, step)
+		if cal.IsBusinessDay(t) {
+			n--
+		}
+	}
+	return t
+}
+
+// NextBusinessTime 返回 t 及以后，最早的工作时间：
+// t 所在的那一天是工作日的话，就是 t，
+// 否则是下一个工作日的零点。
+// 一周的每一天都是周末的话，会 panic
+func (cal *Calendar) NextBusinessTime(t time.Time) time.Time {
+	if cal.IsBusinessDay(t) {
+		return t
+	}
+	return cal.AddBusinessDays(cal.startOfDay(t), 1)
+}
+
+// nextBusinessAt 返回 t 之后，最早的工作日的零点 + at
+func (cal *Calendar) nextBusinessAt(t time.Time, at time.Duration) time.Time {
+	day := cal.startOfDay(t)
+	if !cal.IsBusinessDay(day) {
+		day = cal.AddBusinessDays(day, 1)
+	}
+	for {
+		next := cal.at(day, at)
+		if next.After(t) {
+			return next
+		}
+		day = cal.AddBusinessDays(day, 1)
+	}
+}
+
+// at 返回 day 的零点之后 at 的钟面时间，
+// 例如 at 为 9 小时，就是当天的 09:00，不受夏令时切换的影响
+func (cal *Calendar) at(day time.Time, at time.Duration) time.Time {
+	y, m, d := day.Date()
+	h, rest := at/time.Hour, at%time.Hour
+	mi, rest := rest/time.Minute, rest%time.Minute
+	sec, ns := rest/time.Second, rest%time.Second
+	return time.Date(y, m, d, int(h), int(mi), int(sec), int(ns), cal.loc)
+}
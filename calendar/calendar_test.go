@@ -0,0 +1,89 @@
+package calendar
+
+import (
+	"testing"
+	"time"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+// day 返回 UTC 中 2024 年 m 月 d 日的 h 点
+func day(m time.Month, d, h int) time.Time {
+	return time.Date(2024, m, d, h, 0, 0, 0, time.UTC)
+}
+
+func Test_Calendar(t *testing.T) {
+	Convey("周六和周日休息，元旦放假的日历", t, func() {
+		cal := New(time.UTC)
+		cal.AddHoliday(day(1, 1, 0), "元旦")
+		So(cal.Location(), ShouldEqual, time.UTC)
+		Convey("2024-01-01 是周一，也是节假日", func() {
+			name, ok := cal.Holiday(day(1, 1, 23))
+			So(ok, ShouldBeTrue)
+			So(name, ShouldEqual, "元旦")
+			So(cal.IsWeekend(day(1, 1, 0)), ShouldBeFalse)
+			So(cal.IsBusinessDay(day(1, 1, 0)), ShouldBeFalse)
+		})
+		Convey("周六和周日不是工作日", func() {
+			So(cal.IsWeekend(day(1, 6, 0)), ShouldBeTrue)
+			So(cal.IsBusinessDay(day(1, 6, 0)), ShouldBeFalse)
+			So(cal.IsBusinessDay(day(1, 7, 0)), ShouldBeFalse)
+			So(cal.IsBusinessDay(day(1, 8, 0)), ShouldBeTrue)
+		})
+		Convey("按照日历的时区判断日期", func() {
+			shanghai := time.FixedZone("CST", 8*60*60)
+			// 上海时间的 2024-01-02 07:00，是 UTC 的 2024-01-01 23:00
+			So(cal.IsBusinessDay(time.Date(2024, 1, 2, 7, 0, 0, 0, shanghai)), ShouldBeFalse)
+			So(New(shanghai).IsBusinessDay(time.Date(2024, 1, 2, 7, 0, 0, 0, shanghai)), ShouldBeTrue)
+		})
+		Convey("AddBusinessDays 跳过周末和节假日，保留钟面时间", func() {
+			// 2023-12-29 是周五
+			friday := time.Date(2023, 12, 29, 15, 0, 0, 0, time.UTC)
+			So(cal.AddBusinessDays(friday, 1), ShouldEqual, day(1, 2, 15))
+			So(cal.AddBusinessDays(friday, 5), ShouldEqual, day(1, 8, 15))
+			So(cal.AddBusinessDays(day(1, 6, 15), 1), ShouldEqual, day(1, 8, 15))
+			So(cal.AddBusinessDays(day(1, 2, 15), -1), ShouldEqual, friday)
+			So(cal.AddBusinessDays(day(1, 6, 15), 0), ShouldEqual, day(1, 6, 15))
+		})
+		Convey("NextBusinessTime 在工作日返回 t，否则返回下一个工作日的零点", func() {
+			So(cal.NextBusinessTime(day(1, 2, 15)), ShouldEqual, day(1, 2, 15))
+			So(cal.NextBusinessTime(day(1, 1, 15)), ShouldEqual, day(1, 2, 0))
+			So(cal.NextBusinessTime(day(1, 6, 15)), ShouldEqual, day(1, 8, 0))
+		})
+		Convey("nextBusinessAt 返回 t 之后，工作日的 at 时刻", func() {
+			So(cal.nextBusinessAt(day(1, 2, 8), 9*time.Hour), ShouldEqual, day(1, 2, 9))
+			So(cal.nextBusinessAt(day(1, 2, 9), 9*time.Hour), ShouldEqual, day(1, 3, 9))
+			So(cal.nextBusinessAt(day(1, 5, 10), 9*time.Hour), ShouldEqual, day(1, 8, 9))
+			So(cal.nextBusinessAt(day(1, 1, 8), 9*time.Hour+30*time.Minute), ShouldEqual, day(1, 2, 9).Add(30*time.Minute))
+		})
+	})
+	Convey("自定义周末的日历", t, func() {
+		Convey("周五和周六休息", func() {
+			cal := New(time.UTC, time.Friday, time.Saturday)
+			So(cal.IsBusinessDay(day(1, 5, 0)), ShouldBeFalse)
+			So(cal.IsBusinessDay(day(1, 7, 0)), ShouldBeTrue)
+			// 2024-01-04 是周四
+			So(cal.AddBusinessDays(day(1, 4, 0), 1), ShouldEqual, day(1, 7, 0))
+		})
+		Convey("每天都休息的话，AddBusinessDays 会 panic", func() {
+			cal := New(nil, time.Sunday, time.Monday, time.Tuesday, time.Wednesday, time.Thursday, time.Friday, time.Saturday)
+			So(cal.Location(), ShouldEqual, time.Local)
+			So(func() { cal.AddBusinessDays(day(1, 1, 0), 1) }, ShouldPanic)
+		})
+		Convey("无效的星期，会 panic", func() {
+			So(func() { New(time.UTC, 7) }, ShouldPanicWith, "calendar: 无效的星期 7，必须在 [0, 6] 之内")
+			So(func() { New(time.UTC, time.Saturday, -1) }, ShouldPanic)
+		})
+	})
+	Convey("跨过夏令时切换时，钟面时间不变", t, func() {
+		loc, err := time.LoadLocation("America/New_York")
+		if err != nil {
+			return
+		}
+		cal := New(loc)
+		// 2024-03-10 凌晨切换到夏令时，03-08 是周五
+		friday := time.Date(2024, 3, 8, 9, 0, 0, 0, loc)
+		So(cal.AddBusinessDays(friday, 1), ShouldEqual, time.Date(2024, 3, 11, 9, 0, 0, 0, loc))
+		So(cal.nextBusinessAt(friday, 9*time.Hour), ShouldEqual, time.Date(2024, 3, 11, 9, 0, 0, 0, loc))
+	})
+}
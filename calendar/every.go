@@ -0,0 +1,48 @@
+package calendar
+
+import (
+	"context"
+	"time"
+
+	"github.com/jujili/clock"
+)
+
+// businessDays 是每个工作日 at 时刻的 clock.Recurring
+type businessDays struct {
+	cal *Calendar
+	at  time.Duration
+}
+
+// BusinessDays 返回每个工作日 at 时刻的 clock.Recurring，
+// 可以交给 clock.Every 或者 (*clock.Simulator).Every 使用。
+// at 是当天零点之后的钟面时长，例如 9*time.Hour 就是每个工作日的 09:00。
+// 每次调用 Next 时才会判断工作日，所以之后添加的节假日也会生效。
+// at 不在 [0, 24h) 之内的话，会 panic
+func (cal *Calendar) BusinessDays(at time.Duration) clock.Recurring {
+	if at < 0 || at >= 24*time.Hour {
+		panic("calendar: at 必须在 [0, 24h) 之内")
+	}
+	return businessDays{cal: cal, at: at}
+}
+
+func (r businessDays) Next(t time.Time) time.Time {
+	return r.cal.nextBusinessAt(t, r.at)
+}
+
+// EveryBusinessDay 返回的 *clock.Ticker 在每个工作日的 at 时刻，发送当时的时间。
+// 是 clock.Every 与 BusinessDays(at) 的简写，时间来自 c。
+// 周末和节假日不会发送，下一个时刻在上一个时刻到达时计算，
+// 所以在那之前添加的节假日都会生效。
+//
+// 与 clock.Every 一样，每一个工作日都会发送，不会被丢弃：
+// 没有及时接收的话，下一个工作日的时间会在接收以后才发送。
+// 不再使用时，请调用 Stop。
+// at 不在 [0, 24h) 之内的话，会 panic
+func (cal *Calendar) EveryBusinessDay(c clock.Clock, at time.Duration) *clock.Ticker {
+	r := cal.BusinessDays(at)
+	ctx, cancel := context.WithCancel(clock.Set(context.Background(), c))
+	return &clock.Ticker{
+		C:    clock.Every(ctx, r),
+		Stop: cancel,
+	}
+}
@@ -0,0 +1,88 @@
+package calendar
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/jujili/clock"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func Test_Calendar_EveryBusinessDay(t *testing.T) {
+	Convey("每个工作日的 09:00", t, func() {
+		ctx := context.Background()
+		cal := New(time.UTC)
+		cal.AddHoliday(day(1, 1, 0), "元旦")
+		// 2023-12-29 是周五
+		s := clock.NewSimulator(time.Date(2023, 12, 29, 10, 0, 0, 0, time.UTC))
+		ticker := cal.EveryBusinessDay(s, 9*time.Hour)
+		defer ticker.Stop()
+		Convey("跳过周末和节假日", func() {
+			So(s.BlockUntil(ctx, 1), ShouldBeNil)
+			So(s.Pending()[0], ShouldEqual, day(1, 2, 9))
+			s.Set(day(1, 2, 9))
+			So(<-ticker.C, ShouldEqual, day(1, 2, 9))
+			So(s.BlockUntil(ctx, 1), ShouldBeNil)
+			So(s.Pending()[0], ShouldEqual, day(1, 3, 9))
+		})
+		Convey("没有及时接收的话，每一个工作日依然都会发送", func() {
+			So(s.BlockUntil(ctx, 1), ShouldBeNil)
+			s.Set(day(1, 2, 9))
+			So(s.BlockUntil(ctx, 1), ShouldBeNil)
+			// ticker.C 的缓存被 01-02 占用，01-03 的时间会在接收以后发送
+			s.Set(day(1, 3, 12))
+			So(<-ticker.C, ShouldEqual, day(1, 2, 9))
+			So(<-ticker.C, ShouldEqual, day(1, 3, 9))
+			So(s.BlockUntil(ctx, 1), ShouldBeNil)
+			So(s.Pending()[0], ShouldEqual, day(1, 4, 9))
+		})
+		Convey("下一个时刻之后的节假日，在计算下一个时刻时生效", func() {
+			So(s.BlockUntil(ctx, 1), ShouldBeNil)
+			cal.AddHoliday(day(1, 3, 0), "补休")
+			s.Set(day(1, 2, 9))
+			So(<-ticker.C, ShouldEqual, day(1, 2, 9))
+			So(s.BlockUntil(ctx, 1), ShouldBeNil)
+			So(s.Pending()[0], ShouldEqual, day(1, 4, 9))
+		})
+		Convey("Stop 以后，不再等待", func() {
+			So(s.BlockUntil(ctx, 1), ShouldBeNil)
+			ticker.Stop()
+			ticker.Stop()
+			for i := 0; i < 100 && len(s.Pending()) > 0; i++ {
+				time.Sleep(time.Millisecond)
+			}
+			So(s.Pending(), ShouldBeEmpty)
+		})
+		Convey("at 不在 [0, 24h) 之内的话，会 panic", func() {
+			So(func() { cal.EveryBusinessDay(s, 24*time.Hour) }, ShouldPanic)
+			So(func() { cal.EveryBusinessDay(s, -time.Second) }, ShouldPanic)
+		})
+	})
+}
+
+func Test_Calendar_BusinessDays(t *testing.T) {
+	Convey("BusinessDays 是 clock.Recurring", t, func() {
+		cal := New(time.UTC)
+		cal.AddHoliday(day(1, 1, 0), "元旦")
+		r := cal.BusinessDays(9 * time.Hour)
+		Convey("Next 跳过周末和节假日，不含 t 本身", func() {
+			// 2023-12-29 是周五
+			So(r.Next(time.Date(2023, 12, 29, 8, 0, 0, 0, time.UTC)), ShouldEqual, time.Date(2023, 12, 29, 9, 0, 0, 0, time.UTC))
+			So(r.Next(time.Date(2023, 12, 29, 9, 0, 0, 0, time.UTC)), ShouldEqual, day(1, 2, 9))
+		})
+		Convey("可以交给 Simulator.Every 使用", func() {
+			s := clock.NewSimulator(time.Date(2023, 12, 29, 10, 0, 0, 0, time.UTC))
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+			c := s.Every(ctx, r)
+			s.Set(day(1, 4, 0))
+			So(<-c, ShouldEqual, day(1, 2, 9))
+			So(<-c, ShouldEqual, day(1, 3, 9))
+			So(s.Pending(), ShouldResemble, []time.Time{day(1, 4, 9)})
+		})
+		Convey("at 不在 [0, 24h) 之内的话，会 panic", func() {
+			So(func() { cal.BusinessDays(24 * time.Hour) }, ShouldPanic)
+		})
+	})
+}
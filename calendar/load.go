@@ -0,0 +1,156 @@
+package calendar
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+)
+
+// ErrInvalidHoliday 表示节假日列表的格式不正确
+var ErrInvalidHoliday = errors.New("calendar: 无效的节假日")
+
+// LoadText 从 r 中读取节假日，每行一个：
+//
+//	# 2024 年的节假日
+//	2024-01-01 元旦
+//	2024-02-10 春节
+//
+// 日期的格式是 2006-01-02，日期之后的内容是节假日的名称，可以省略。
+// 空行和以 # 开头的行会被忽略。
+// 出错的话，已经读取的节假日依然有效。
+func (cal *Calendar) LoadText(r io.Reader) error {
+	scanner := bufio.NewScanner(r)
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" || strings.HasPrefix(text, "#") {
+			continue
+		}
+		value, name := text, ""
+		if i := strings.IndexAny(text, " \t"); i >= 0 {
+			value, name = text[:i], strings.TrimSpace(text[i:])
+		}
+		day, err := time.ParseInLocation("2006-01-02", value, cal.loc)
+		if err != nil {
+			return fmt.Errorf("%w: 第 %d 行 %q", ErrInvalidHoliday, line, text)
+		}
+		cal.AddHoliday(day, name)
+	}
+	return scanner.Err()
+}
+
+// holidayJSON 是 LoadJSON 读取的节假日
+type holidayJSON struct {
+	Date string `json:"date"`
+	Name string `json:"name"`
+}
+
+// LoadJSON 从 r 中读取 JSON 数组格式的节假日：
+//
+//	[{"date": "2024-01-01", "name": "元旦"}]
+//
+// 日期的格式是 2006-01-02。
+// 出错的话，不会添加任何节假日。
+func (cal *Calendar) LoadJSON(r io.Reader) error {
+	var list []holidayJSON
+	if err := json.NewDecoder(r).Decode(&list); err != nil {
+		return fmt.Errorf("%w: %s", ErrInvalidHoliday, err)
+	}
+	days := make([]time.Time, len(list))
+	for i, h := range list {
+		day, err := time.ParseInLocation("2006-01-02", h.Date, cal.loc)
+		if err != nil {
+			return fmt.Errorf("%w: 第 %d 个 %q", ErrInvalidHoliday, i+1, h.Date)
+		}
+		days[i] = day
+	}
+	for i, h := range list {
+		cal.AddHoliday(days[i], h.Name)
+	}
+	return nil
+}
+
+// LoadICal 从 r 中读取 iCalendar 格式的节假日，只支持其中的一小部分：
+//
+//	BEGIN:VEVENT
+//	DTSTART;VALUE=DATE:20240210
+//	DTEND;VALUE=DATE:20240218
+//	SUMMARY:春节
+//	END:VEVENT
+//
+// 每个 VEVENT 是一个节假日，DTSTART 是第一天，
+// DTEND 是结束后的那一天，可以省略，省略的话，只有一天。
+// 带有时刻的 DTSTART 和 DTEND，例如 20240210T090000Z，只使用其中的日期。
+// 其他的属性和折行都会被忽略。
+// 出错的话，已经读取的节假日依然有效。
+func (cal *Calendar) LoadICal(r io.Reader) error {
+	scanner := bufio.NewScanner(r)
+	var inEvent bool
+	var start, end time.Time
+	var name string
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimRight(scanner.Text(), "\r")
+		key, value := splitProperty(text)
+		var err error
+		switch {
+		case key == "BEGIN" && value == "VEVENT":
+			inEvent = true
+			start, end, name = time.Time{}, time.Time{}, ""
+		case key == "END" && value == "VEVENT":
+			if !inEvent || start.IsZero() {
+				return fmt.Errorf("%w: 第 %d 行，VEVENT 没有 DTSTART", ErrInvalidHoliday, line)
+			}
+			inEvent = false
+			cal.addRange(start, end, name)
+		case !inEvent:
+		case key == "DTSTART":
+			start, err = cal.parseICalDate(value)
+		case key == "DTEND":
+			end, err = cal.parseICalDate(value)
+		case key == "SUMMARY":
+			name = value
+		}
+		if err != nil {
+			return fmt.Errorf("%w: 第 %d 行 %q", ErrInvalidHoliday, line, text)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return err
+	}
+	if inEvent {
+		return fmt.Errorf("%w: VEVENT 没有 END", ErrInvalidHoliday)
+	}
+	return nil
+}
+
+// splitProperty 把 iCalendar 的一行，拆分成属性名和值，属性名中的参数会被去掉
+func splitProperty(text string) (key, value string) {
+	i := strings.Index(text, ":")
+	if i < 0 {
+		return "", ""
+	}
+	key, value = text[:i], text[i+1:]
+	if j := strings.Index(key, ";"); j >= 0 {
+		key = key[:j]
+	}
+	return strings.ToUpper(key), strings.TrimSpace(value)
+}
+
+// parseICalDate 解析 20060102 格式的日期，忽略其后的时刻
+func (cal *Calendar) parseICalDate(value string) (time.Time, error) {
+	if len(value) < 8 {
+		return time.Time{}, ErrInvalidHoliday
+	}
+	return time.ParseInLocation("20060102", value[:8], cal.loc)
+}
+
+// addRange 把 [start, end) 中的每一天设置为节假日，end 不晚于 start 的话，只有 start 一天
+func (cal *Calendar) addRange(start, end time.Time, name string) {
+	cal.AddHoliday(start, name)
+	for day := cal.addDays(start, 1); day.Before(end); day = cal.addDays(day, 1) {
+		cal.AddHoliday(day, name)
+	}
+}
@@ -0,0 +1,103 @@
+package calendar
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func Test_Calendar_LoadText(t *testing.T) {
+	Convey("从文本中读取节假日", t, func() {
+		cal := New(time.UTC)
+		Convey("忽略空行和注释，名称可以省略", func() {
+			err := cal.LoadText(strings.NewReader("# 2024\n\n2024-01-01 元旦\n2024-02-10\t春节 初一\n  2024-05-01\n"))
+			So(err, ShouldBeNil)
+			name, _ := cal.Holiday(day(1, 1, 0))
+			So(name, ShouldEqual, "元旦")
+			name, _ = cal.Holiday(day(2, 10, 0))
+			So(name, ShouldEqual, "春节 初一")
+			name, ok := cal.Holiday(day(5, 1, 0))
+			So(ok, ShouldBeTrue)
+			So(name, ShouldEqual, "")
+		})
+		Convey("日期格式错误时，报告行号", func() {
+			err := cal.LoadText(strings.NewReader("2024-01-01 元旦\n2024/02/10 春节\n"))
+			So(errors.Is(err, ErrInvalidHoliday), ShouldBeTrue)
+			So(err.Error(), ShouldContainSubstring, "第 2 行")
+			_, ok := cal.Holiday(day(1, 1, 0))
+			So(ok, ShouldBeTrue)
+		})
+	})
+}
+
+func Test_Calendar_LoadJSON(t *testing.T) {
+	Convey("从 JSON 中读取节假日", t, func() {
+		cal := New(time.UTC)
+		Convey("读取日期和名称", func() {
+			err := cal.LoadJSON(strings.NewReader(`[{"date": "2024-01-01", "name": "元旦"}, {"date": "2024-05-01"}]`))
+			So(err, ShouldBeNil)
+			name, _ := cal.Holiday(day(1, 1, 0))
+			So(name, ShouldEqual, "元旦")
+			So(cal.IsBusinessDay(day(5, 1, 0)), ShouldBeFalse)
+		})
+		Convey("出错的话，不会添加任何节假日", func() {
+			err := cal.LoadJSON(strings.NewReader(`[{"date": "2024-01-01"}, {"date": "20240501"}]`))
+			So(errors.Is(err, ErrInvalidHoliday), ShouldBeTrue)
+			So(err.Error(), ShouldContainSubstring, "第 2 个")
+			So(cal.IsBusinessDay(day(1, 1, 0)), ShouldBeTrue)
+		})
+		Convey("不是 JSON 数组", func() {
+			err := cal.LoadJSON(strings.NewReader(`{"date": "2024-01-01"}`))
+			So(errors.Is(err, ErrInvalidHoliday), ShouldBeTrue)
+		})
+	})
+}
+
+func Test_Calendar_LoadICal(t *testing.T) {
+	Convey("从 iCalendar 中读取节假日", t, func() {
+		cal := New(time.UTC)
+		Convey("DTEND 是结束后的那一天，省略的话，只有一天", func() {
+			err := cal.LoadICal(strings.NewReader(strings.Join([]string{
+				"BEGIN:VCALENDAR",
+				"VERSION:2.0",
+				"BEGIN:VEVENT",
+				"DTSTART;VALUE=DATE:20240210",
+				"DTEND;VALUE=DATE:20240213",
+				"SUMMARY:春节",
+				"END:VEVENT",
+				"BEGIN:VEVENT",
+				"SUMMARY:劳动节",
+				"DTSTART:20240501T000000Z",
+				"END:VEVENT",
+				"END:VCALENDAR",
+			}, "\r\n")))
+			So(err, ShouldBeNil)
+			for d := 10; d < 13; d++ {
+				name, ok := cal.Holiday(day(2, d, 0))
+				So(ok, ShouldBeTrue)
+				So(name, ShouldEqual, "春节")
+			}
+			So(cal.IsBusinessDay(day(2, 13, 0)), ShouldBeTrue)
+			_, ok := cal.Holiday(day(2, 13, 0))
+			So(ok, ShouldBeFalse)
+			name, _ := cal.Holiday(day(5, 1, 0))
+			So(name, ShouldEqual, "劳动节")
+		})
+		Convey("VEVENT 没有 DTSTART", func() {
+			err := cal.LoadICal(strings.NewReader("BEGIN:VEVENT\nSUMMARY:元旦\nEND:VEVENT\n"))
+			So(errors.Is(err, ErrInvalidHoliday), ShouldBeTrue)
+			So(err.Error(), ShouldContainSubstring, "第 3 行")
+		})
+		Convey("日期格式错误", func() {
+			err := cal.LoadICal(strings.NewReader("BEGIN:VEVENT\nDTSTART:2024\nEND:VEVENT\n"))
+			So(errors.Is(err, ErrInvalidHoliday), ShouldBeTrue)
+		})
+		Convey("VEVENT 没有 END", func() {
+			err := cal.LoadICal(strings.NewReader("BEGIN:VEVENT\nDTSTART:20240101\n"))
+			So(errors.Is(err, ErrInvalidHoliday), ShouldBeTrue)
+		})
+	})
+}
@@ -0,0 +1,123 @@
+package clock
+
+import (
+	"fmt"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// SyncCallbacks 打开同步回调模式：
+// Add，Set，Move 和 Run* 会等到这一次触发的 AfterFunc 回调全部返回以后，才返回。
+// 这样的话，紧跟在 Add 后面的断言，就可以看到回调的结果。
+//
+// 回调在 timeout（真实时间）内没有全部返回的话，会 panic，
+// 并列出没有返回的回调是在哪里创建的。
+// timeout <= 0 时，关闭同步回调模式，回调的运行与 time.AfterFunc 一样，不再等待。
+//
+// NOTICE: 只有 AfterFunc 的回调会被等待，
+// 从 Timer.C 接收到时间的 goroutine，仍然不保证已经运行。
+// 回调中可以再次调用 Add 等方法，但是不能等待触发它的那一次 Add 返回。
+func (s *Simulator) SyncCallbacks(timeout time.Duration) {
+	atomic.StoreInt64(&s.syncTimeout, int64(timeout))
+}
+
+// callbackStep 记录了一次 Add，Set，Move 或 Run* 触发的 AfterFunc 回调
+type callbackStep struct {
+	timeout time.Duration
+	wg      sync.WaitGroup
+	mu      sync.Mutex
+	// running 是还没有返回的回调的创建位置
+	running map[int]string
+	next    int
+}
+
+// newStep 在同步回调模式下，返回记录回调的 *callbackStep，否则返回 nil
+func (s *Simulator) newStep() *callbackStep {
+	timeout := time.Duration(atomic.LoadInt64(&s.syncTimeout))
+	if timeout <= 0 {
+		return nil
+	}
+	return &callbackStep{
+		timeout: timeout,
+		running: make(map[int]string),
+	}
+}
+
+// goCallback 在新的 goroutine 中运行 f，
+// 如果 st 不是 nil，st 会记录 f，直到 f 返回。
+func (st *callbackStep) goCallback(f func(), site string) {
+	if st == nil {
+		go f()
+		return
+	}
+	st.mu.Lock()
+	id := st.next
+	st.next++
+	st.running[id] = site
+	st.mu.Unlock()
+	st.wg.Add(1)
+	go func() {
+		defer func() {
+			st.mu.Lock()
+			delete(st.running, id)
+			st.mu.Unlock()
+			st.wg.Done()
+		}()
+		f()
+	}()
+}
+
+// wait 等待 st 记录的回调全部返回，超时的话 panic
+// NOTICE: 务必在临界区外运行此方法，否则回调中的 Simulator 方法会死锁
+func (st *callbackStep) wait() {
+	if st == nil {
+		return
+	}
+	done := make(chan struct{})
+	go func() {
+		st.wg.Wait()
+		close(done)
+	}()
+	timer := time.NewTimer(st.timeout)
+	defer timer.Stop()
+	select {
+	case <-done:
+	case <-timer.C:
+		st.mu.Lock()
+		sites := make([]string, 0, len(st.running))
+		for _, site := range st.running {
+			sites = append(sites, "\t"+site)
+		}
+		st.mu.Unlock()
+		sort.Strings(sites)
+		panic(fmt.Sprintf("clock: %d 个 AfterFunc 回调在 %s 内没有返回，可能被阻塞了，创建于：\n%s",
+			len(sites), st.timeout, strings.Join(sites, "\n")))
+	}
+}
+
+// pkgDir 是 clock 包所在的目录，用于在调用栈中跳过 clock 包自己的函数
+var pkgDir = func() string {
+	_, file, _, _ := runtime.Caller(0)
+	return filepath.Dir(file)
+}()
+
+// callerSite 返回调用栈中，第一个不属于 clock 包的位置
+func callerSite() string {
+	pcs := make([]uintptr, 16)
+	n := runtime.Callers(2, pcs)
+	frames := runtime.CallersFrames(pcs[:n])
+	for {
+		f, more := frames.Next()
+		if filepath.Dir(f.File) != pkgDir || strings.HasSuffix(f.File, "_test.go") {
+			return fmt.Sprintf("%s:%d", f.File, f.Line)
+		}
+		if !more {
+			return "unknown"
+		}
+	}
+}
@@ -0,0 +1,68 @@
+package clock
+
+import (
+	"sync/atomic"
+	"testing"
+	"time"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func Test_Simulator_SyncCallbacks(t *testing.T) {
+	Convey("同步回调模式", t, func() {
+		now := time.Date(2020, 5, 20, 0, 0, 0, 0, time.UTC)
+		s := NewSimulator(now)
+		s.SyncCallbacks(time.Second)
+		var count int32
+		slow := func() {
+			// 让回调明显慢于 Add 的返回
+			time.Sleep(10 * time.Millisecond)
+			atomic.AddInt32(&count, 1)
+		}
+		Convey("Add 返回时，回调已经运行完毕", func() {
+			s.AfterFunc(time.Second, slow)
+			s.AfterFunc(2*time.Second, slow)
+			s.Add(2 * time.Second)
+			So(atomic.LoadInt32(&count), ShouldEqual, 2)
+		})
+		Convey("Set，Move 和 RunFor 也会等待", func() {
+			s.AfterFunc(time.Second, slow)
+			s.AfterFunc(2*time.Second, slow)
+			s.AfterFunc(3*time.Second, slow)
+			s.Set(now.Add(time.Second))
+			So(atomic.LoadInt32(&count), ShouldEqual, 1)
+			s.Move()
+			So(atomic.LoadInt32(&count), ShouldEqual, 2)
+			s.RunFor(time.Second)
+			So(atomic.LoadInt32(&count), ShouldEqual, 3)
+		})
+		Convey("回调中可以驱动 Simulator", func() {
+			s.AfterFunc(time.Second, func() {
+				s.AfterFunc(time.Second, slow)
+				s.Add(time.Second)
+			})
+			s.Add(time.Second)
+			So(atomic.LoadInt32(&count), ShouldEqual, 1)
+			So(s.Now(), ShouldEqual, now.Add(2*time.Second))
+		})
+		Convey("回调被阻塞时，panic 并给出创建的位置", func() {
+			s.SyncCallbacks(20 * time.Millisecond)
+			block := make(chan struct{})
+			defer close(block)
+			s.AfterFunc(time.Second, func() { <-block })
+			var msg string
+			func() {
+				defer func() { msg, _ = recover().(string) }()
+				s.Add(time.Second)
+			}()
+			So(msg, ShouldContainSubstring, "1 个 AfterFunc 回调在 20ms 内没有返回")
+			So(msg, ShouldContainSubstring, "callback_test.go:")
+		})
+		Convey("关闭以后，不再等待", func() {
+			s.SyncCallbacks(0)
+			s.AfterFunc(time.Second, slow)
+			s.Add(time.Second)
+			So(atomic.LoadInt32(&count), ShouldEqual, 0)
+		})
+	})
+}
@@ -0,0 +1,107 @@
+package clockhttp
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Client 通过 NewHandler 提供的接口，远程驱动 Simulator
+type Client struct {
+	// BaseURL 是 handler 挂载的地址，例如 http://127.0.0.1:8080/clock，
+	// 挂载在 /clock 下的 handler 需要使用 http.StripPrefix，详见包的文档
+	BaseURL string
+	// HTTPClient 为 nil 时，使用 http.DefaultClient
+	HTTPClient *http.Client
+}
+
+// NewClient 返回访问 baseURL 的 *Client
+func NewClient(baseURL string) *Client {
+	return &Client{BaseURL: strings.TrimRight(baseURL, "/")}
+}
+
+// Error 是 handler 返回的错误
+type Error struct {
+	StatusCode int
+	Message    string
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("clockhttp: %d %s", e.StatusCode, e.Message)
+}
+
+// Now 返回远端 Simulator 的当前时间
+func (c *Client) Now(ctx context.Context) (time.Time, error) {
+	st, err := c.do(ctx, http.MethodGet, "/now", nil)
+	return st.Now, err
+}
+
+// Add 让远端 Simulator 前进 d，返回前进后的时间
+func (c *Client) Add(ctx context.Context, d time.Duration) (time.Time, error) {
+	st, err := c.do(ctx, http.MethodPost, "/add", url.Values{"d": {d.String()}})
+	return st.Now, err
+}
+
+// Set 把远端 Simulator 的单调时间设置为 t，返回前进的时长
+func (c *Client) Set(ctx context.Context, t time.Time) (time.Duration, error) {
+	st, err := c.do(ctx, http.MethodPost, "/set", url.Values{"t": {t.Format(time.RFC3339Nano)}})
+	return time.Duration(st.Advanced), err
+}
+
+// Move 让远端 Simulator 前进到下一个任务的到期时间，
+// 返回前进后的时间和前进的时长
+func (c *Client) Move(ctx context.Context) (time.Time, time.Duration, error) {
+	st, err := c.do(ctx, http.MethodPost, "/move", nil)
+	return st.Now, time.Duration(st.Advanced), err
+}
+
+// Pending 返回远端 Simulator 中所有等待触发的任务的到期时间
+func (c *Client) Pending(ctx context.Context) ([]time.Time, error) {
+	st, err := c.do(ctx, http.MethodGet, "/pending", nil)
+	return st.Pending, err
+}
+
+// BlockUntil 阻塞到远端 Simulator 中至少有 n 个等待触发的任务为止。
+// timeout > 0 时，由远端在 timeout 后放弃等待，
+// 此时返回的是 StatusCode 为 504（http.StatusGatewayTimeout）的 *Error。
+func (c *Client) BlockUntil(ctx context.Context, n int, timeout time.Duration) ([]time.Time, error) {
+	v := url.Values{"n": {strconv.Itoa(n)}}
+	if timeout > 0 {
+		v.Set("timeout", timeout.String())
+	}
+	st, err := c.do(ctx, http.MethodPost, "/block", v)
+	return st.Pending, err
+}
+
+func (c *Client) do(ctx context.Context, method, path string, v url.Values) (State, error) {
+	var st State
+	u := c.BaseURL + path
+	if len(v) > 0 {
+		u += "?" + v.Encode()
+	}
+	req, err := http.NewRequest(method, u, nil)
+	if err != nil {
+		return st, err
+	}
+	hc := c.HTTPClient
+	if hc == nil {
+		hc = http.DefaultClient
+	}
+	resp, err := hc.Do(req.WithContext(ctx))
+	if err != nil {
+		return st, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		var body errorBody
+		json.NewDecoder(resp.Body).Decode(&body)
+		return st, &Error{StatusCode: resp.StatusCode, Message: body.Error}
+	}
+	err = json.NewDecoder(resp.Body).Decode(&st)
+	return st, err
+}
@@ -0,0 +1,73 @@
+package clockhttp
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/jujili/clock"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func Test_Client(t *testing.T) {
+	Convey("使用 Client 远程驱动 Simulator", t, func() {
+		now := time.Date(2020, 5, 20, 0, 0, 0, 0, time.UTC)
+		s := clock.NewSimulator(now)
+		mux := http.NewServeMux()
+		mux.Handle("/clock/", http.StripPrefix("/clock", NewHandler(s)))
+		server := httptest.NewServer(mux)
+		defer server.Close()
+		c := NewClient(server.URL + "/clock/")
+		ctx := context.Background()
+		Convey("Now", func() {
+			t, err := c.Now(ctx)
+			So(err, ShouldBeNil)
+			So(t, ShouldEqual, now)
+		})
+		Convey("Add", func() {
+			t, err := c.Add(ctx, time.Second)
+			So(err, ShouldBeNil)
+			So(t, ShouldEqual, now.Add(time.Second))
+		})
+		Convey("Set", func() {
+			d, err := c.Set(ctx, now.Add(time.Hour))
+			So(err, ShouldBeNil)
+			So(d, ShouldEqual, time.Hour)
+			So(s.Now(), ShouldEqual, now.Add(time.Hour))
+		})
+		Convey("Set 让时间逆转时，返回 *Error", func() {
+			_, err := c.Set(ctx, now.Add(-time.Hour))
+			var e *Error
+			So(errors.As(err, &e), ShouldBeTrue)
+			So(e.StatusCode, ShouldEqual, http.StatusBadRequest)
+			So(e.Message, ShouldNotBeEmpty)
+		})
+		Convey("等待服务创建 timer 后，再推动时间", func() {
+			fired := make(chan time.Time, 1)
+			go func() {
+				time.Sleep(10 * time.Millisecond)
+				fired <- <-s.After(time.Minute)
+			}()
+			pending, err := c.BlockUntil(ctx, 1, time.Second)
+			So(err, ShouldBeNil)
+			So(pending, ShouldResemble, []time.Time{now.Add(time.Minute)})
+			pending, err = c.Pending(ctx)
+			So(err, ShouldBeNil)
+			So(len(pending), ShouldEqual, 1)
+			t, d, err := c.Move(ctx)
+			So(err, ShouldBeNil)
+			So(t, ShouldEqual, now.Add(time.Minute))
+			So(d, ShouldEqual, time.Minute)
+			So(<-fired, ShouldEqual, now.Add(time.Minute))
+		})
+		Convey("BlockUntil 超时", func() {
+			_, err := c.BlockUntil(ctx, 1, 10*time.Millisecond)
+			var e *Error
+			So(errors.As(err, &e), ShouldBeTrue)
+			So(e.StatusCode, ShouldEqual, http.StatusGatewayTimeout)
+		})
+	})
+}
@@ -0,0 +1,111 @@
+package clockhttp
+
+import (
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"sync"
+
+	"github.com/jujili/clock"
+)
+
+// EnvAddr 是远程控制接口的监听地址，例如 127.0.0.1:7777
+const EnvAddr = "CLOCK_HTTP"
+
+// FromEnv 与 clock.FromEnv 一样，根据环境变量选择时钟。
+// CLOCK_MODE=sim 且设置了 CLOCK_HTTP 时，
+// 会在 CLOCK_HTTP 上启动 NewHandler，让测试可以远程驱动时间，
+// 否则返回的 *Server 为 nil。
+// 不再需要远程控制的时候，请关闭 *Server，nil 的 *Server 也可以关闭。
+//
+//	c, srv, err := clockhttp.FromEnv()
+//	defer srv.Close()
+//	ctx := clock.Set(context.Background(), c)
+func FromEnv() (clock.Clock, *Server, error) {
+	return fromLookup(os.LookupEnv)
+}
+
+// Server 是控制 *clock.Simulator 的 HTTP 服务
+type Server struct {
+	ln   net.Listener
+	done chan struct{}
+
+	mu     sync.Mutex
+	closed bool
+	err    error
+}
+
+// Listen 在 addr 上启动控制 s 的 HTTP 服务，
+// 调用返回的 *Server 的 Close 就可以停止服务。
+func Listen(addr string, s *clock.Simulator) (*Server, error) {
+	ln, err := net.Listen("tcp", addr)
+	if err != nil {
+		return nil, err
+	}
+	srv := &Server{
+		ln:   ln,
+		done: make(chan struct{}),
+	}
+	go srv.serve(NewHandler(s))
+	return srv, nil
+}
+
+func (srv *Server) serve(h http.Handler) {
+	defer close(srv.done)
+	err := http.Serve(srv.ln, h)
+	srv.mu.Lock()
+	defer srv.mu.Unlock()
+	// Close 引起的错误不需要报告
+	if !srv.closed {
+		srv.err = err
+	}
+}
+
+// Addr 返回服务监听的地址
+func (srv *Server) Addr() net.Addr {
+	return srv.ln.Addr()
+}
+
+// Err 返回服务意外停止的原因，服务还在运行或者是被 Close 停止的话，返回 nil
+func (srv *Server) Err() error {
+	srv.mu.Lock()
+	defer srv.mu.Unlock()
+	return srv.err
+}
+
+// Close 停止服务，等到服务停止以后才返回。
+// 服务在 Close 之前就意外停止的话，返回停止的原因。
+// srv 为 nil 的话，什么也不做。
+func (srv *Server) Close() error {
+	if srv == nil {
+		return nil
+	}
+	srv.mu.Lock()
+	srv.closed = true
+	srv.mu.Unlock()
+	// http.Serve 返回时也会关闭 ln，所以忽略这里的错误
+	srv.ln.Close()
+	<-srv.done
+	return srv.Err()
+}
+
+func fromLookup(lookup func(string) (string, bool)) (clock.Clock, *Server, error) {
+	c, err := clock.FromLookup(lookup)
+	if err != nil {
+		return nil, nil, err
+	}
+	addr, ok := lookup(EnvAddr)
+	if !ok || addr == "" {
+		return c, nil, nil
+	}
+	s, ok := c.(*clock.Simulator)
+	if !ok {
+		return nil, nil, fmt.Errorf("clockhttp: %s 需要 %s=sim", EnvAddr, clock.EnvMode)
+	}
+	srv, err := Listen(addr, s)
+	if err != nil {
+		return nil, nil, err
+	}
+	return s, srv, nil
+}
@@ -0,0 +1,68 @@
+package clockhttp
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/jujili/clock"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func Test_fromLookup(t *testing.T) {
+	Convey("根据变量选择时钟，并启动远程控制", t, func() {
+		vars := map[string]string{
+			clock.EnvMode:  "sim",
+			clock.EnvStart: "2020-05-20T00:00:00Z",
+			EnvAddr:        "127.0.0.1:0",
+		}
+		lookup := func(key string) (string, bool) {
+			v, ok := vars[key]
+			return v, ok
+		}
+		Convey("sim 时钟可以被远程驱动", func() {
+			c, srv, err := fromLookup(lookup)
+			So(err, ShouldBeNil)
+			defer srv.Close()
+			client := NewClient("http://" + srv.Addr().String())
+			now, err := client.Add(context.Background(), time.Hour)
+			So(err, ShouldBeNil)
+			So(now, ShouldEqual, time.Date(2020, 5, 20, 1, 0, 0, 0, time.UTC))
+			So(c.Now(), ShouldEqual, now)
+		})
+		Convey("没有设置地址时，不会启动服务", func() {
+			delete(vars, EnvAddr)
+			c, srv, err := fromLookup(lookup)
+			So(err, ShouldBeNil)
+			So(srv, ShouldBeNil)
+			So(srv.Close(), ShouldBeNil)
+			So(c, ShouldHaveSameTypeAs, &clock.Simulator{})
+		})
+		Convey("只有 sim 时钟可以被远程驱动", func() {
+			vars[clock.EnvMode] = "real"
+			_, _, err := fromLookup(lookup)
+			So(err, ShouldNotBeNil)
+		})
+		Convey("Close 会停止服务", func() {
+			_, srv, err := fromLookup(lookup)
+			So(err, ShouldBeNil)
+			So(srv.Close(), ShouldBeNil)
+			So(srv.Err(), ShouldBeNil)
+			_, err = NewClient("http://"+srv.Addr().String()).Add(context.Background(), time.Hour)
+			So(err, ShouldNotBeNil)
+		})
+		Convey("服务意外停止的话，Close 返回停止的原因", func() {
+			_, srv, err := fromLookup(lookup)
+			So(err, ShouldBeNil)
+			srv.ln.Close()
+			<-srv.done
+			So(srv.Err(), ShouldNotBeNil)
+			So(srv.Close(), ShouldEqual, srv.Err())
+		})
+		Convey("变量错误", func() {
+			vars[clock.EnvMode] = "fast"
+			_, _, err := fromLookup(lookup)
+			So(err, ShouldNotBeNil)
+		})
+	})
+}
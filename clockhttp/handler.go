@@ -0,0 +1,190 @@
+// Package clockhttp 通过 HTTP 远程控制正在运行的 *clock.Simulator。
+//
+// 以模拟时钟启动的服务，挂载 NewHandler 后，
+// 黑盒测试就可以使用 Client 在本机驱动服务的时间。
+//
+// 所有的时间都使用 RFC 3339 格式（带纳秒），时长使用 time.ParseDuration 的格式。
+//
+//	GET  /now                       读取当前时间
+//	POST /add?d=1s                  Add
+//	POST /set?t=2020-05-20T00:00:00Z Set
+//	POST /move                      Move
+//	GET  /pending                   列出所有等待触发的任务
+//	POST /block?n=2&timeout=5s      阻塞到至少有 n 个等待触发的任务
+//
+// 参数错误时返回 400，/block 等待超时返回 504，方法错误返回 405，
+// 错误的内容是 {"error": "..."}。
+//
+// NewHandler 按照上面的绝对路径分发请求，
+// 挂载在其他路径下的话，需要使用 http.StripPrefix 去掉前缀：
+//
+//	mux.Handle("/clock/", http.StripPrefix("/clock", clockhttp.NewHandler(s)))
+//
+// 然后使用 NewClient("http://127.0.0.1:8080/clock") 访问。
+package clockhttp
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/jujili/clock"
+)
+
+// State 是所有接口的返回值
+type State struct {
+	Now time.Time `json:"now"`
+	// Advanced 是本次操作推进的时长，只有 /set 和 /move 会设置
+	Advanced Duration `json:"advanced,omitempty"`
+	// Pending 只有 /pending 和 /block 会设置
+	Pending []time.Time `json:"pending,omitempty"`
+}
+
+// Duration 在 JSON 中编码为 time.Duration.String() 的格式
+type Duration time.Duration
+
+// MarshalJSON 实现了 json.Marshaler 接口
+func (d Duration) MarshalJSON() ([]byte, error) {
+	return json.Marshal(time.Duration(d).String())
+}
+
+// UnmarshalJSON 实现了 json.Unmarshaler 接口
+func (d *Duration) UnmarshalJSON(data []byte) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		return err
+	}
+	v, err := time.ParseDuration(s)
+	if err != nil {
+		return err
+	}
+	*d = Duration(v)
+	return nil
+}
+
+type errorBody struct {
+	Error string `json:"error"`
+}
+
+type handler struct {
+	s   *clock.Simulator
+	mux *http.ServeMux
+}
+
+// NewHandler 返回控制 s 的 http.Handler
+func NewHandler(s *clock.Simulator) http.Handler {
+	h := &handler{
+		s:   s,
+		mux: http.NewServeMux(),
+	}
+	h.mux.HandleFunc("/now", h.only(http.MethodGet, h.now))
+	h.mux.HandleFunc("/add", h.only(http.MethodPost, h.add))
+	h.mux.HandleFunc("/set", h.only(http.MethodPost, h.set))
+	h.mux.HandleFunc("/move", h.only(http.MethodPost, h.move))
+	h.mux.HandleFunc("/pending", h.only(http.MethodGet, h.pending))
+	h.mux.HandleFunc("/block", h.only(http.MethodPost, h.block))
+	return h
+}
+
+func (h *handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	h.mux.ServeHTTP(w, r)
+}
+
+func (h *handler) only(method string, f func(w http.ResponseWriter, r *http.Request)) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != method {
+			w.Header().Set("Allow", method)
+			writeError(w, http.StatusMethodNotAllowed, fmt.Errorf("只支持 %s 方法", method))
+			return
+		}
+		f(w, r)
+	}
+}
+
+func (h *handler) now(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, State{Now: h.s.Now()})
+}
+
+func (h *handler) add(w http.ResponseWriter, r *http.Request) {
+	d, err := time.ParseDuration(r.FormValue("d"))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	if d < 0 {
+		writeError(w, http.StatusBadRequest, fmt.Errorf("d 不能为负数：%s", d))
+		return
+	}
+	writeJSON(w, http.StatusOK, State{Now: h.s.Add(d)})
+}
+
+func (h *handler) set(w http.ResponseWriter, r *http.Request) {
+	t, err := time.Parse(time.RFC3339Nano, r.FormValue("t"))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	if t.Before(h.s.MonotonicNow()) {
+		writeError(w, http.StatusBadRequest, fmt.Errorf("t 早于当前的单调时间 %s", h.s.MonotonicNow().Format(time.RFC3339Nano)))
+		return
+	}
+	d := h.s.Set(t)
+	writeJSON(w, http.StatusOK, State{Now: h.s.Now(), Advanced: Duration(d)})
+}
+
+func (h *handler) move(w http.ResponseWriter, r *http.Request) {
+	now, d := h.s.Move()
+	writeJSON(w, http.StatusOK, State{Now: now, Advanced: Duration(d)})
+}
+
+func (h *handler) pending(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, State{Now: h.s.Now(), Pending: h.s.Pending()})
+}
+
+func (h *handler) block(w http.ResponseWriter, r *http.Request) {
+	n, err := strconv.Atoi(r.FormValue("n"))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	ctx := r.Context()
+	if v := r.FormValue("timeout"); v != "" {
+		timeout, err := time.ParseDuration(v)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, err)
+			return
+		}
+		// 超时使用的是真实时间，而不是被控制的模拟时间
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+	if err := h.s.BlockUntil(ctx, n); err != nil {
+		writeError(w, http.StatusGatewayTimeout, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, State{Now: h.s.Now(), Pending: h.s.Pending()})
+}
+
+// writeJSON 先编码 v，编码失败的话，返回 500。
+// 响应已经开始发送，无法再改变状态码，所以写入失败时只能记录日志
+func writeJSON(w http.ResponseWriter, code int, v interface{}) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		code = http.StatusInternalServerError
+		data, _ = json.Marshal(errorBody{Error: err.Error()})
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	if _, err := w.Write(append(data, '\n')); err != nil {
+		log.Printf("clockhttp: 写入响应失败：%v", err)
+	}
+}
+
+func writeError(w http.ResponseWriter, code int, err error) {
+	writeJSON(w, code, errorBody{Error: err.Error()})
+}
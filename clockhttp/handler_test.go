@@ -0,0 +1,123 @@
+package clockhttp
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/jujili/clock"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func serve(h http.Handler, method, target string) (*httptest.ResponseRecorder, State) {
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest(method, target, nil))
+	var st State
+	json.Unmarshal(w.Body.Bytes(), &st)
+	return w, st
+}
+
+func Test_Handler(t *testing.T) {
+	Convey("使用 Handler 控制 Simulator", t, func() {
+		now := time.Date(2020, 5, 20, 0, 0, 0, 0, time.UTC)
+		s := clock.NewSimulator(now)
+		h := NewHandler(s)
+		Convey("GET /now", func() {
+			w, st := serve(h, http.MethodGet, "/now")
+			So(w.Code, ShouldEqual, http.StatusOK)
+			So(w.Header().Get("Content-Type"), ShouldEqual, "application/json")
+			So(st.Now, ShouldEqual, now)
+		})
+		Convey("POST /add", func() {
+			w, st := serve(h, http.MethodPost, "/add?d=1m30s")
+			So(w.Code, ShouldEqual, http.StatusOK)
+			So(st.Now, ShouldEqual, now.Add(90*time.Second))
+			So(s.Now(), ShouldEqual, now.Add(90*time.Second))
+		})
+		Convey("POST /add 的 d 不能为负数", func() {
+			w, _ := serve(h, http.MethodPost, "/add?d=-1s")
+			So(w.Code, ShouldEqual, http.StatusBadRequest)
+			So(s.Now(), ShouldEqual, now)
+		})
+		Convey("POST /set", func() {
+			w, st := serve(h, http.MethodPost, "/set?t=2020-05-20T01:00:00Z")
+			So(w.Code, ShouldEqual, http.StatusOK)
+			So(st.Now, ShouldEqual, now.Add(time.Hour))
+			So(time.Duration(st.Advanced), ShouldEqual, time.Hour)
+		})
+		Convey("POST /set 不能让时间逆转", func() {
+			s.Add(time.Hour)
+			w, _ := serve(h, http.MethodPost, "/set?t=2020-05-20T00:30:00Z")
+			So(w.Code, ShouldEqual, http.StatusBadRequest)
+			So(s.Now(), ShouldEqual, now.Add(time.Hour))
+		})
+		Convey("POST /set 的时间格式错误", func() {
+			w, _ := serve(h, http.MethodPost, "/set?t=yesterday")
+			So(w.Code, ShouldEqual, http.StatusBadRequest)
+			var body errorBody
+			json.Unmarshal(w.Body.Bytes(), &body)
+			So(body.Error, ShouldNotBeEmpty)
+		})
+		Convey("POST /move 和 GET /pending", func() {
+			s.AfterFunc(time.Minute, func() {})
+			s.AfterFunc(time.Hour, func() {})
+			w, st := serve(h, http.MethodGet, "/pending")
+			So(w.Code, ShouldEqual, http.StatusOK)
+			So(len(st.Pending), ShouldEqual, 2)
+			So(st.Pending[0], ShouldEqual, now.Add(time.Minute))
+			So(st.Pending[1], ShouldEqual, now.Add(time.Hour))
+			w, st = serve(h, http.MethodPost, "/move")
+			So(w.Code, ShouldEqual, http.StatusOK)
+			So(st.Now, ShouldEqual, now.Add(time.Minute))
+			So(time.Duration(st.Advanced), ShouldEqual, time.Minute)
+		})
+		Convey("POST /block 已经满足条件", func() {
+			s.AfterFunc(time.Minute, func() {})
+			w, st := serve(h, http.MethodPost, "/block?n=1&timeout=1s")
+			So(w.Code, ShouldEqual, http.StatusOK)
+			So(len(st.Pending), ShouldEqual, 1)
+		})
+		Convey("POST /block 会等待新的任务", func() {
+			go func() {
+				time.Sleep(10 * time.Millisecond)
+				s.AfterFunc(time.Minute, func() {})
+			}()
+			w, st := serve(h, http.MethodPost, "/block?n=1&timeout=5s")
+			So(w.Code, ShouldEqual, http.StatusOK)
+			So(len(st.Pending), ShouldEqual, 1)
+		})
+		Convey("POST /block 超时", func() {
+			w, _ := serve(h, http.MethodPost, "/block?n=1&timeout=10ms")
+			So(w.Code, ShouldEqual, http.StatusGatewayTimeout)
+		})
+		Convey("POST /block 的 n 格式错误", func() {
+			w, _ := serve(h, http.MethodPost, "/block?n=many")
+			So(w.Code, ShouldEqual, http.StatusBadRequest)
+		})
+		Convey("错误的方法", func() {
+			w, _ := serve(h, http.MethodGet, "/move")
+			So(w.Code, ShouldEqual, http.StatusMethodNotAllowed)
+			So(w.Header().Get("Allow"), ShouldEqual, http.MethodPost)
+		})
+		Convey("使用 http.StripPrefix 挂载在其他路径下", func() {
+			mux := http.NewServeMux()
+			mux.Handle("/clock/", http.StripPrefix("/clock", h))
+			w, st := serve(mux, http.MethodPost, "/clock/add?d=1s")
+			So(w.Code, ShouldEqual, http.StatusOK)
+			So(st.Now, ShouldEqual, now.Add(time.Second))
+		})
+	})
+}
+
+func Test_writeJSON(t *testing.T) {
+	Convey("无法编码的值，返回 500", t, func() {
+		w := httptest.NewRecorder()
+		writeJSON(w, http.StatusOK, make(chan int))
+		So(w.Code, ShouldEqual, http.StatusInternalServerError)
+		var body errorBody
+		So(json.Unmarshal(w.Body.Bytes(), &body), ShouldBeNil)
+		So(body.Error, ShouldNotBeEmpty)
+	})
+}
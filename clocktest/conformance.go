@@ -0,0 +1,418 @@
+package clocktest
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+const (
+	// unit 是检查中使用的时长单位，对于真实时钟，也要足够长，避免调度带来的误差
+	unit = 100 * time.Millisecond
+	// wait 是等待 channel 收到值的最长真实时间
+	wait = time.Second
+	// settle 是检查 channel 没有收到值之前，等待的真实时间
+	settle = 10 * time.Millisecond
+)
+
+// Conformance 逐项检查 factory 返回的时钟，与 time 和 context 标准库的行为是否一致：
+//
+//   - Timer 的 Stop 和 Reset 的返回值
+//   - 0 和负数的时长
+//   - Ticker 在没有接收时，丢弃多余的时间
+//   - 上下文的 Err 和 Done 的先后顺序
+//   - 子上下文继承父上下文更早的 deadline
+//
+// 每一项检查都是 t 的一个子测试，并使用一个新的 Driver。
+// 对于真实的时钟，整个检查需要几秒钟。
+func Conformance(t *testing.T, factory Factory) {
+	for _, c := range cases {
+		c := c
+		t.Run(c.name, func(t *testing.T) {
+			c.check(t, factory())
+		})
+	}
+}
+
+var cases = []struct {
+	name  string
+	check func(t *testing.T, d Driver)
+}{
+	{"Timer 到期后，Stop 返回 false 的话，C 中的时间依然可以接收", timerStopAfterFire},
+	{"Timer 到期前，第一次 Stop 返回 true，之后返回 false", timerStopBeforeFire},
+	{"Timer 到期后，Reset 返回 false，并会再次到期", timerResetAfterFire},
+	{"Timer 到期前，Reset 返回 true，并推迟到期时间", timerResetBeforeFire},
+	{"0 和负数时长的 Timer 与 After 立即到期", zeroTimer},
+	{"0 和负数时长的 Sleep 立即返回", zeroSleep},
+	{"AfterFunc 的 C 是 nil，到期后运行 f", afterFunc},
+	{"AfterFunc 到期前 Stop，f 不会运行", afterFuncStop},
+	{"Ticker 没有被接收时，丢弃多余的时间", tickerDrop},
+	{"Ticker Stop 以后，不再发送时间", tickerStop},
+	{"NewTicker 的时长不是正数时 panic，Tick 返回 nil", tickerNonPositive},
+	{"Since 和 Until 基于 Now", sinceUntil},
+	{"ContextWithTimeout 到期后，先关闭 Done，Err 才返回 DeadlineExceeded", contextTimeout},
+	{"cancel 返回后，Done 已经关闭，Err 返回 Canceled", contextCancel},
+	{"到期后再 cancel，Err 依然是 DeadlineExceeded", contextCancelAfterTimeout},
+	{"父上下文取消后，子上下文也被取消", contextParentCancel},
+	{"子上下文继承父上下文更早的 deadline", contextInheritDeadline},
+	{"子上下文的 deadline 更早时，使用自己的 deadline", contextOwnDeadline},
+	{"已经过去的 deadline 和不是正数的 timeout，上下文立即结束", contextExpired},
+}
+
+// recv 在 wait 内从 c 接收到值的话，返回 true
+func recv(c <-chan time.Time) bool {
+	select {
+	case <-c:
+		return true
+	case <-time.After(wait):
+		return false
+	}
+}
+
+// none 等待 settle 以后，c 中没有值的话，返回 true
+func none(c <-chan time.Time) bool {
+	time.Sleep(settle)
+	select {
+	case <-c:
+		return false
+	default:
+		return true
+	}
+}
+
+// closed 在 wait 内 done 被关闭的话，返回 true
+func closed(done <-chan struct{}) bool {
+	select {
+	case <-done:
+		return true
+	case <-time.After(wait):
+		return false
+	}
+}
+
+// isClosed 返回 done 此刻是否已经关闭
+func isClosed(done <-chan struct{}) bool {
+	select {
+	case <-done:
+		return true
+	default:
+		return false
+	}
+}
+
+// NOTICE: Go 1.23 以后，time.Timer.C 没有缓存，到期后还没有被接收的话，Stop 会返回 true，
+// 所以这里只检查 if !t.Stop() { <-t.C } 的用法不会阻塞。
+func timerStopAfterFire(t *testing.T, d Driver) {
+	timer := d.Clock().NewTimer(unit)
+	d.Advance(unit)
+	if !timer.Stop() && !recv(timer.C) {
+		t.Error("Stop 返回 false，但是没有从 C 接收到到期的时间")
+	}
+	if !none(timer.C) {
+		t.Error("Stop 以后，C 依然收到了时间")
+	}
+}
+
+func timerStopBeforeFire(t *testing.T, d Driver) {
+	timer := d.Clock().NewTimer(unit)
+	if !timer.Stop() {
+		t.Error("第一次 Stop 返回了 false")
+	}
+	if timer.Stop() {
+		t.Error("第二次 Stop 返回了 true")
+	}
+	d.Advance(2 * unit)
+	if !none(timer.C) {
+		t.Error("Stop 以后，C 依然收到了时间")
+	}
+}
+
+func timerResetAfterFire(t *testing.T, d Driver) {
+	timer := d.Clock().NewTimer(unit)
+	d.Advance(unit)
+	if !recv(timer.C) {
+		t.Fatal("没有从 C 接收到到期的时间")
+	}
+	if timer.Reset(unit) {
+		t.Error("到期后 Reset 返回了 true")
+	}
+	d.Advance(unit)
+	if !recv(timer.C) {
+		t.Error("Reset 以后，没有再次到期")
+	}
+	if timer.Stop() {
+		t.Error("再次到期后 Stop 返回了 true")
+	}
+}
+
+func timerResetBeforeFire(t *testing.T, d Driver) {
+	timer := d.Clock().NewTimer(unit)
+	if !timer.Reset(2 * unit) {
+		t.Error("到期前 Reset 返回了 false")
+	}
+	d.Advance(unit)
+	if !none(timer.C) {
+		t.Error("Reset 以后，依然在原来的时间到期")
+	}
+	d.Advance(unit)
+	if !recv(timer.C) {
+		t.Error("Reset 以后，没有在新的时间到期")
+	}
+}
+
+func zeroTimer(t *testing.T, d Driver) {
+	c := d.Clock()
+	zero, negative := c.NewTimer(0), c.NewTimer(-unit)
+	after := c.After(0)
+	d.Advance(0)
+	if !recv(zero.C) {
+		t.Error("NewTimer(0) 没有到期")
+	}
+	if !recv(negative.C) {
+		t.Error("NewTimer(-d) 没有到期")
+	}
+	if !recv(after) {
+		t.Error("After(0) 没有到期")
+	}
+	if zero.Stop() || negative.Stop() {
+		t.Error("到期后 Stop 返回了 true")
+	}
+}
+
+func zeroSleep(t *testing.T, d Driver) {
+	c := d.Clock()
+	for _, dur := range []time.Duration{0, -unit} {
+		done := make(chan struct{})
+		go func(dur time.Duration) {
+			c.Sleep(dur)
+			close(done)
+		}(dur)
+		if !closed(done) {
+			t.Errorf("Sleep(%s) 没有立即返回", dur)
+		}
+	}
+}
+
+func afterFunc(t *testing.T, d Driver) {
+	fired := make(chan time.Time, 2)
+	f := func() { fired <- time.Time{} }
+	timer := d.Clock().AfterFunc(unit, f)
+	if timer.C != nil {
+		t.Error("AfterFunc 的 C 不是 nil")
+	}
+	d.Advance(unit)
+	if !recv(fired) {
+		t.Fatal("到期后，没有运行 f")
+	}
+	if timer.Stop() {
+		t.Error("到期后 Stop 返回了 true")
+	}
+	if timer.Reset(0) {
+		t.Error("到期后 Reset 返回了 true")
+	}
+	d.Advance(0)
+	if !recv(fired) {
+		t.Error("Reset(0) 以后，没有再次运行 f")
+	}
+}
+
+func afterFuncStop(t *testing.T, d Driver) {
+	fired := make(chan time.Time, 1)
+	timer := d.Clock().AfterFunc(unit, func() { fired <- time.Time{} })
+	if !timer.Stop() {
+		t.Error("到期前 Stop 返回了 false")
+	}
+	d.Advance(2 * unit)
+	if !none(fired) {
+		t.Error("Stop 以后，依然运行了 f")
+	}
+}
+
+func tickerDrop(t *testing.T, d Driver) {
+	ticker := d.Clock().NewTicker(unit)
+	defer ticker.Stop()
+	d.Advance(3*unit + unit/2)
+	if !recv(ticker.C) {
+		t.Fatal("经过 3 个周期后，没有收到时间")
+	}
+	if !none(ticker.C) {
+		t.Error("多余的时间没有被丢弃")
+	}
+	d.Advance(unit)
+	if !recv(ticker.C) {
+		t.Error("接收以后，没有收到下一个周期的时间")
+	}
+}
+
+func tickerStop(t *testing.T, d Driver) {
+	ticker := d.Clock().NewTicker(unit)
+	d.Advance(unit)
+	if !recv(ticker.C) {
+		t.Fatal("没有收到第一个周期的时间")
+	}
+	ticker.Stop()
+	d.Advance(2 * unit)
+	if !none(ticker.C) {
+		t.Error("Stop 以后，依然收到了时间")
+	}
+}
+
+func tickerNonPositive(t *testing.T, d Driver) {
+	c := d.Clock()
+	for _, dur := range []time.Duration{0, -unit} {
+		func() {
+			defer func() {
+				if recover() == nil {
+					t.Errorf("NewTicker(%s) 没有 panic", dur)
+				}
+			}()
+			c.NewTicker(dur)
+		}()
+		if c.Tick(dur) != nil {
+			t.Errorf("Tick(%s) 没有返回 nil", dur)
+		}
+	}
+}
+
+func sinceUntil(t *testing.T, d Driver) {
+	c := d.Clock()
+	start := c.Now()
+	d.Advance(unit)
+	if since := c.Since(start); since < unit {
+		t.Errorf("前进 %s 后，Since 返回了 %s", unit, since)
+	}
+	if until := c.Until(start.Add(10 * unit)); until <= 0 || until > 9*unit {
+		t.Errorf("前进 %s 后，Until(start+%s) 返回了 %s", unit, 10*unit, until)
+	}
+	if c.Now().Before(start) {
+		t.Error("Now 倒退了")
+	}
+}
+
+func contextTimeout(t *testing.T, d Driver) {
+	c := d.Clock()
+	before := c.Now()
+	ctx, cancel := c.ContextWithTimeout(context.Background(), unit)
+	defer cancel()
+	after := c.Now()
+	deadline, ok := ctx.Deadline()
+	if !ok {
+		t.Fatal("没有 deadline")
+	}
+	if deadline.Sub(before) < unit || deadline.Sub(after) > unit {
+		t.Errorf("deadline 是 %s，应该在 [%s, %s] 之间", deadline, before.Add(unit), after.Add(unit))
+	}
+	if isClosed(ctx.Done()) || ctx.Err() != nil {
+		t.Error("到期前，上下文已经结束")
+	}
+	d.Advance(unit)
+	if !closed(ctx.Done()) {
+		t.Fatal("到期后，没有关闭 Done")
+	}
+	if err := ctx.Err(); err != context.DeadlineExceeded {
+		t.Errorf("Done 关闭以后，Err 返回了 %v", err)
+	}
+}
+
+func contextCancel(t *testing.T, d Driver) {
+	c := d.Clock()
+	ctx, cancel := c.ContextWithTimeout(context.Background(), unit)
+	cancel()
+	if !isClosed(ctx.Done()) {
+		t.Error("cancel 返回后，Done 没有关闭")
+	}
+	if err := ctx.Err(); err != context.Canceled {
+		t.Errorf("cancel 返回后，Err 返回了 %v", err)
+	}
+}
+
+func contextCancelAfterTimeout(t *testing.T, d Driver) {
+	ctx, cancel := d.Clock().ContextWithTimeout(context.Background(), unit)
+	d.Advance(unit)
+	if !closed(ctx.Done()) {
+		t.Fatal("到期后，没有关闭 Done")
+	}
+	cancel()
+	if err := ctx.Err(); err != context.DeadlineExceeded {
+		t.Errorf("到期后再 cancel，Err 返回了 %v", err)
+	}
+}
+
+func contextParentCancel(t *testing.T, d Driver) {
+	parent, cancelParent := context.WithCancel(context.Background())
+	ctx, cancel := d.Clock().ContextWithTimeout(parent, 10*unit)
+	defer cancel()
+	cancelParent()
+	if !closed(ctx.Done()) {
+		t.Fatal("父上下文取消后，子上下文的 Done 没有关闭")
+	}
+	if err := ctx.Err(); err != context.Canceled {
+		t.Errorf("父上下文取消后，子上下文的 Err 返回了 %v", err)
+	}
+}
+
+func contextInheritDeadline(t *testing.T, d Driver) {
+	c := d.Clock()
+	parent, cancelParent := c.ContextWithTimeout(context.Background(), unit)
+	defer cancelParent()
+	ctx, cancel := c.ContextWithTimeout(parent, 10*unit)
+	defer cancel()
+	pd, _ := parent.Deadline()
+	if deadline, ok := ctx.Deadline(); !ok || !deadline.Equal(pd) {
+		t.Errorf("子上下文的 deadline 是 %s，应该是父上下文的 %s", deadline, pd)
+	}
+	d.Advance(unit)
+	if !closed(ctx.Done()) {
+		t.Fatal("父上下文到期后，子上下文的 Done 没有关闭")
+	}
+	if err := ctx.Err(); err != context.DeadlineExceeded {
+		t.Errorf("父上下文到期后，子上下文的 Err 返回了 %v", err)
+	}
+}
+
+func contextOwnDeadline(t *testing.T, d Driver) {
+	c := d.Clock()
+	parent, cancelParent := c.ContextWithTimeout(context.Background(), 10*unit)
+	defer cancelParent()
+	deadline := c.Now().Add(unit)
+	ctx, cancel := c.ContextWithDeadline(parent, deadline)
+	defer cancel()
+	if got, ok := ctx.Deadline(); !ok || !got.Equal(deadline) {
+		t.Errorf("子上下文的 deadline 是 %s，应该是 %s", got, deadline)
+	}
+	d.Advance(unit)
+	if !closed(ctx.Done()) {
+		t.Fatal("到期后，子上下文的 Done 没有关闭")
+	}
+	if err := ctx.Err(); err != context.DeadlineExceeded {
+		t.Errorf("到期后，子上下文的 Err 返回了 %v", err)
+	}
+	if parent.Err() != nil {
+		t.Error("子上下文到期后，父上下文也结束了")
+	}
+}
+
+func contextExpired(t *testing.T, d Driver) {
+	c := d.Clock()
+	contexts := map[string]func() (context.Context, context.CancelFunc){
+		"ContextWithDeadline(过去的时间)": func() (context.Context, context.CancelFunc) {
+			return c.ContextWithDeadline(context.Background(), c.Now().Add(-unit))
+		},
+		"ContextWithTimeout(0)": func() (context.Context, context.CancelFunc) {
+			return c.ContextWithTimeout(context.Background(), 0)
+		},
+		"ContextWithTimeout(-d)": func() (context.Context, context.CancelFunc) {
+			return c.ContextWithTimeout(context.Background(), -unit)
+		},
+	}
+	for name, newContext := range contexts {
+		ctx, cancel := newContext()
+		if !isClosed(ctx.Done()) {
+			t.Errorf("%s 返回的上下文，Done 没有立即关闭", name)
+		}
+		if err := ctx.Err(); err != context.DeadlineExceeded {
+			t.Errorf("%s 返回的上下文，Err 返回了 %v", name, err)
+		}
+		cancel()
+	}
+}
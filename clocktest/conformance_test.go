@@ -0,0 +1,88 @@
+package clocktest
+
+import (
+	"testing"
+	"time"
+
+	"github.com/jujili/clock"
+)
+
+func Test_Conformance_realClock(t *testing.T) {
+	if testing.Short() {
+		t.Skip("真实时钟的检查需要几秒钟")
+	}
+	Conformance(t, func() Driver {
+		return Real(clock.NewRealClock())
+	})
+}
+
+func Test_Conformance_offsetClock(t *testing.T) {
+	if testing.Short() {
+		t.Skip("真实时钟的检查需要几秒钟")
+	}
+	Conformance(t, func() Driver {
+		return Real(clock.NewOffsetClock(time.Hour))
+	})
+}
+
+func Test_Conformance_scaledClock(t *testing.T) {
+	if testing.Short() {
+		t.Skip("真实时钟的检查需要几秒钟")
+	}
+	const scale = 2
+	Conformance(t, func() Driver {
+		c := clock.NewScaledClock(time.Now(), scale)
+		// 时钟的 d 只需要真实的 d/scale，slack 则是给 runtime 的真实时间
+		return NewDriver(c, func(d time.Duration) {
+			time.Sleep(d/scale + slack)
+		})
+	})
+}
+
+func Test_Conformance_Simulator(t *testing.T) {
+	Conformance(t, func() Driver {
+		return Sim(clock.NewSimulator(time.Now()))
+	})
+}
+
+func Test_Conformance_Actor(t *testing.T) {
+	Conformance(t, func() Driver {
+		s := clock.NewSimulator(time.Now())
+		return NewDriver(s.Actor("actor"), func(d time.Duration) {
+			s.Add(d)
+		})
+	})
+}
+
+func Test_Conformance_Node(t *testing.T) {
+	Conformance(t, func() Driver {
+		s := clock.NewSimulator(time.Now())
+		n := clock.NewClockGroup(s).Node("node")
+		n.SetOffset(time.Hour)
+		return NewDriver(n, func(d time.Duration) {
+			s.Add(d)
+		})
+	})
+}
+
+func Test_Conformance_PausableClock(t *testing.T) {
+	if testing.Short() {
+		t.Skip("真实时钟的检查需要几秒钟")
+	}
+	Conformance(t, func() Driver {
+		p := clock.NewPausableClock()
+		t.Cleanup(p.Close)
+		return Real(p)
+	})
+}
+
+func Test_Conformance_PausableClock_paused(t *testing.T) {
+	Conformance(t, func() Driver {
+		p := clock.NewPausableClock()
+		t.Cleanup(p.Close)
+		p.Pause()
+		return NewDriver(p, func(d time.Duration) {
+			p.Step(d)
+		})
+	})
+}
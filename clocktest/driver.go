@@ -0,0 +1,68 @@
+// Package clocktest 检查 clock.Clock 的实现，是否与 time 和 context 标准库的行为一致。
+//
+// 自定义的时钟，只要提供一个 Driver，就可以复用 Conformance 中的全部检查：
+//
+//	func TestMyClock(t *testing.T) {
+//		clocktest.Conformance(t, func() clocktest.Driver {
+//			c := NewMyClock()
+//			return clocktest.NewDriver(c, func(d time.Duration) {
+//				c.Add(d)
+//			})
+//		})
+//	}
+package clocktest
+
+import (
+	"time"
+
+	"github.com/jujili/clock"
+)
+
+// Driver 把被检查的时钟，与推动它的时间前进的方法组合在一起
+type Driver interface {
+	// Clock 返回被检查的时钟
+	Clock() clock.Clock
+	// Advance 让时钟前进 d，并触发在此期间到期的任务。
+	// d 为 0 时，也要触发已经到期的任务。
+	Advance(d time.Duration)
+}
+
+// Factory 为每一项检查返回一个全新的 Driver
+type Factory func() Driver
+
+// slack 是真实时钟在 Advance 时多等待的时间，
+// 让 runtime 有机会把到期的时间发送出去。
+const slack = 10 * time.Millisecond
+
+type driver struct {
+	c       clock.Clock
+	advance func(d time.Duration)
+}
+
+// NewDriver 返回用 advance 推动 c 的 Driver
+func NewDriver(c clock.Clock, advance func(d time.Duration)) Driver {
+	return &driver{c: c, advance: advance}
+}
+
+// Real 返回跟随真实时间流逝的 c 的 Driver，Advance 会真的等待 d。
+// 例如 clock.NewRealClock() 和 clock.NewOffsetClock(offset)。
+func Real(c clock.Clock) Driver {
+	return NewDriver(c, func(d time.Duration) {
+		time.Sleep(d + slack)
+	})
+}
+
+// Sim 返回 s 的 Driver，Advance 就是 s.Add
+func Sim(s *clock.Simulator) Driver {
+	return NewDriver(s, func(d time.Duration) {
+		s.Add(d)
+	})
+}
+
+func (d *driver) Clock() clock.Clock {
+	return d.c
+}
+
+func (d *driver) Advance(dur time.Duration) {
+	d.advance(dur)
+}
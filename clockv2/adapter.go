@@ -0,0 +1,146 @@
+package clockv2
+
+import (
+	"context"
+	"time"
+
+	"github.com/jujili/clock"
+)
+
+// NewRealClock 返回标准库中真实时间的时钟
+func NewRealClock() Clock {
+	return FromV1(clock.NewRealClock())
+}
+
+// FromV1 把 clock.Clock 转换成 Clock。
+// c 是 *clock.Simulator 的话，返回 *Simulator，可以继续驱动。
+// c 是 ToV1 的返回值的话，返回原来的 Clock。
+func FromV1(c clock.Clock) Clock {
+	switch c := c.(type) {
+	case *clock.Simulator:
+		return &Simulator{Simulator: c}
+	case v2Clock:
+		return c.Clock
+	default:
+		return v1Clock{c}
+	}
+}
+
+// ToV1 把 Clock 转换成 clock.Clock，返回的 Timer 和 Ticker 是结构体。
+// c 是 *Simulator 的话，返回其中的 *clock.Simulator。
+// c 是 FromV1 的返回值的话，返回原来的 clock.Clock。
+func ToV1(c Clock) clock.Clock {
+	switch c := c.(type) {
+	case *Simulator:
+		return c.Simulator
+	case v1Clock:
+		return c.Clock
+	default:
+		return v2Clock{c}
+	}
+}
+
+// TimerFromV1 把 *clock.Timer 转换成 Timer
+func TimerFromV1(t *clock.Timer) Timer {
+	return v1Timer{t}
+}
+
+// TimerToV1 把 Timer 转换成 *clock.Timer
+// t 是 TimerFromV1 的返回值的话，返回原来的 *clock.Timer
+func TimerToV1(t Timer) *clock.Timer {
+	switch t := t.(type) {
+	case v1Timer:
+		return t.t
+	case afterFuncTimer:
+		return t.t
+	}
+	return &clock.Timer{
+		C:     t.C(),
+		Stop:  t.Stop,
+		Reset: t.Reset,
+	}
+}
+
+// TickerFromV1 把 *clock.Ticker 转换成 Ticker
+func TickerFromV1(t *clock.Ticker) Ticker {
+	return v1Ticker{t}
+}
+
+// TickerToV1 把 Ticker 转换成 *clock.Ticker
+// t 是 TickerFromV1 的返回值的话，返回原来的 *clock.Ticker
+func TickerToV1(t Ticker) *clock.Ticker {
+	if t, ok := t.(v1Ticker); ok {
+		return t.t
+	}
+	return &clock.Ticker{
+		C:    t.C(),
+		Stop: t.Stop,
+	}
+}
+
+type v1Timer struct {
+	t *clock.Timer
+}
+
+func (t v1Timer) C() <-chan time.Time        { return t.t.C }
+func (t v1Timer) Stop() bool                 { return t.t.Stop() }
+func (t v1Timer) Reset(d time.Duration) bool { return t.t.Reset(d) }
+
+// afterFuncTimer 是 AfterFunc 返回的 Timer，C 总是 nil
+type afterFuncTimer struct {
+	v1Timer
+}
+
+func (afterFuncTimer) C() <-chan time.Time { return nil }
+
+type v1Ticker struct {
+	t *clock.Ticker
+}
+
+func (t v1Ticker) C() <-chan time.Time { return t.t.C }
+func (t v1Ticker) Stop()               { t.t.Stop() }
+
+// v1Clock 用 clock.Clock 实现 Clock
+type v1Clock struct {
+	clock.Clock
+}
+
+func (c v1Clock) AfterFunc(d time.Duration, f func()) Timer {
+	return afterFuncTimer{v1Timer{c.Clock.AfterFunc(d, f)}}
+}
+
+func (c v1Clock) NewTicker(d time.Duration) Ticker {
+	return TickerFromV1(c.Clock.NewTicker(d))
+}
+
+func (c v1Clock) NewTimer(d time.Duration) Timer {
+	return TimerFromV1(c.Clock.NewTimer(d))
+}
+
+// v2Clock 用 Clock 实现 clock.Clock
+type v2Clock struct {
+	Clock
+}
+
+func (c v2Clock) AfterFunc(d time.Duration, f func()) *clock.Timer {
+	return TimerToV1(c.Clock.AfterFunc(d, f))
+}
+
+func (c v2Clock) NewTicker(d time.Duration) *clock.Ticker {
+	return TickerToV1(c.Clock.NewTicker(d))
+}
+
+func (c v2Clock) NewTimer(d time.Duration) *clock.Timer {
+	return TimerToV1(c.Clock.NewTimer(d))
+}
+
+// ContextWithDeadline 返回的上下文中的时钟是 c，
+// 以便 clock.Now(ctx) 等封装函数使用同一个时钟。
+func (c v2Clock) ContextWithDeadline(parent context.Context, d time.Time) (context.Context, context.CancelFunc) {
+	return c.Clock.ContextWithDeadline(clock.Set(parent, c), d)
+}
+
+// ContextWithTimeout 返回的上下文中的时钟是 c
+func (c v2Clock) ContextWithTimeout(parent context.Context, timeout time.Duration) (context.Context, context.CancelFunc) {
+	return c.Clock.ContextWithTimeout(clock.Set(parent, c), timeout)
+}
@@ -0,0 +1,81 @@
+package clockv2
+
+import (
+	"testing"
+	"time"
+
+	. "github.com/golang/mock/gomock"
+	"github.com/jujili/clock"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func Test_FromV1(t *testing.T) {
+	Convey("把 clock.Clock 转换成 Clock", t, func() {
+		Convey("*clock.Simulator 转换成 *Simulator", func() {
+			s := clock.NewSimulator(time.Now())
+			c := FromV1(s)
+			v2, ok := c.(*Simulator)
+			So(ok, ShouldBeTrue)
+			So(v2.Simulator, ShouldEqual, s)
+			So(ToV1(c), ShouldEqual, s)
+		})
+		Convey("其他的 clock.Clock 可以转换回去", func() {
+			real := clock.NewRealClock()
+			c := FromV1(real)
+			So(ToV1(c) == real, ShouldBeTrue)
+			timer := c.NewTimer(time.Hour)
+			So(timer.C(), ShouldNotBeNil)
+			So(timer.Stop(), ShouldBeTrue)
+			So(timer.Reset(time.Hour), ShouldBeFalse)
+			So(timer.Stop(), ShouldBeTrue)
+			ticker := c.NewTicker(time.Hour)
+			So(ticker.C(), ShouldNotBeNil)
+			ticker.Stop()
+			af := c.AfterFunc(time.Hour, func() {})
+			So(af.C(), ShouldBeNil)
+			So(af.Stop(), ShouldBeTrue)
+		})
+	})
+}
+
+func Test_ToV1(t *testing.T) {
+	Convey("把 Clock 转换成 clock.Clock", t, func() {
+		ctrl := NewController(t)
+		defer ctrl.Finish()
+		c := make(chan time.Time, 1)
+		mockTimer := NewMockTimer(ctrl)
+		mockTimer.EXPECT().C().Return(c)
+		mockTimer.EXPECT().Stop().Return(true)
+		mockTimer.EXPECT().Reset(time.Second).Return(false)
+		mockClock := NewMockClock(ctrl)
+		mockClock.EXPECT().NewTimer(time.Minute).Return(mockTimer)
+		v1 := ToV1(mockClock)
+		Convey("返回的 *clock.Timer 调用的是 Timer 的方法", func() {
+			timer := v1.NewTimer(time.Minute)
+			So(timer.C, ShouldEqual, (<-chan time.Time)(c))
+			So(timer.Stop(), ShouldBeTrue)
+			So(timer.Reset(time.Second), ShouldBeFalse)
+			So(FromV1(v1), ShouldEqual, mockClock)
+		})
+	})
+}
+
+func Test_TimerToV1(t *testing.T) {
+	Convey("Timer 和 *clock.Timer 相互转换", t, func() {
+		s := clock.NewSimulator(time.Now())
+		v1 := s.NewTimer(time.Second)
+		So(TimerToV1(TimerFromV1(v1)), ShouldEqual, v1)
+		ticker := s.NewTicker(time.Second)
+		So(TickerToV1(TickerFromV1(ticker)), ShouldEqual, ticker)
+		Convey("其他的 Ticker 会被包装", func() {
+			ctrl := NewController(t)
+			defer ctrl.Finish()
+			mockTicker := NewMockTicker(ctrl)
+			mockTicker.EXPECT().C().Return(nil)
+			mockTicker.EXPECT().Stop()
+			t := TickerToV1(mockTicker)
+			So(t.C, ShouldBeNil)
+			t.Stop()
+		})
+	})
+}
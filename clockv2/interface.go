@@ -0,0 +1,54 @@
+// Package clockv2 是 clock 的第二版 API。
+//
+// 与 clock 包最大的不同是，NewTimer 和 AfterFunc 返回的 Timer，
+// 以及 NewTicker 返回的 Ticker，都是接口，而不是带有函数字段的结构体。
+// 所以，可以使用 gomock 生成 mock，也可以方便地包装。
+//
+// 迁移期间，使用 FromV1 和 ToV1 在两个版本的 Clock 之间转换，
+// 使用 TimerFromV1，TimerToV1，TickerFromV1 和 TickerToV1 在两个版本的 Timer 和 Ticker 之间转换。
+package clockv2
+
+import (
+	"context"
+	"time"
+)
+
+// Clock 与 clock.Clock 的方法一样，
+// 只是 AfterFunc，NewTimer 和 NewTicker 返回的是接口。
+type Clock interface {
+	After(d time.Duration) <-chan time.Time
+	AfterFunc(d time.Duration, f func()) Timer
+	NewTicker(d time.Duration) Ticker
+	NewTimer(d time.Duration) Timer
+	Now() time.Time
+	Since(t time.Time) time.Duration
+	Sleep(d time.Duration)
+	Tick(d time.Duration) <-chan time.Time
+	Until(t time.Time) time.Duration
+
+	// ContextWithDeadline 与 context.ContextWithDeadline 具有相同的功能
+	// 只是基于 Clock 的时间线
+	ContextWithDeadline(parent context.Context, d time.Time) (context.Context, context.CancelFunc)
+	// ContextWithTimeout 是 ContextWithDeadline(parent, Now(parent).Add(timeout)).
+	ContextWithTimeout(parent context.Context, timeout time.Duration) (context.Context, context.CancelFunc)
+}
+
+// Timer 替代 time.Timer
+type Timer interface {
+	// C 返回发送到期时间的 channel，AfterFunc 创建的 Timer 返回 nil
+	C() <-chan time.Time
+	// Stop prevents the Timer from firing.
+	// It returns true if the call stops the timer, false if the timer has already expired or been stopped.
+	Stop() bool
+	// Reset changes the timer to expire after duration d.
+	// It returns true if the timer had been active, false if the timer had expired or been stopped.
+	Reset(d time.Duration) bool
+}
+
+// Ticker 替代 time.Ticker
+type Ticker interface {
+	// C 返回发送 tick 的 channel
+	C() <-chan time.Time
+	// Stop turns off a ticker. After Stop, no more ticks will be sent.
+	Stop()
+}
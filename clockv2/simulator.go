@@ -0,0 +1,35 @@
+package clockv2
+
+import (
+	"time"
+
+	"github.com/jujili/clock"
+)
+
+// Simulator 是实现了 Clock 接口的 *clock.Simulator，
+// Add，Set，Move 等驱动时钟的方法与 *clock.Simulator 相同。
+type Simulator struct {
+	*clock.Simulator
+}
+
+// NewSimulator 返回一个以 now 为当前时间的虚拟时钟
+func NewSimulator(now time.Time) *Simulator {
+	return &Simulator{Simulator: clock.NewSimulator(now)}
+}
+
+// AfterFunc waits for the duration to elapse and then calls f in its own goroutine.
+func (s *Simulator) AfterFunc(d time.Duration, f func()) Timer {
+	return afterFuncTimer{v1Timer{s.Simulator.AfterFunc(d, f)}}
+}
+
+// NewTicker returns a new Ticker containing a channel that will send the
+// current time with a period specified by the duration d.
+func (s *Simulator) NewTicker(d time.Duration) Ticker {
+	return TickerFromV1(s.Simulator.NewTicker(d))
+}
+
+// NewTimer creates a new Timer that will send the current time on its channel
+// after at least duration d.
+func (s *Simulator) NewTimer(d time.Duration) Timer {
+	return TimerFromV1(s.Simulator.NewTimer(d))
+}
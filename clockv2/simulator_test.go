@@ -0,0 +1,46 @@
+package clockv2
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/jujili/clock"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func Test_Simulator(t *testing.T) {
+	Convey("v2 的 Simulator", t, func() {
+		now := time.Date(2020, 5, 20, 0, 0, 0, 0, time.UTC)
+		s := NewSimulator(now)
+		var c Clock = s
+		Convey("Timer 是接口", func() {
+			timer := c.NewTimer(time.Second)
+			s.Add(time.Second)
+			So(<-timer.C(), ShouldEqual, now.Add(time.Second))
+			So(timer.Reset(time.Second), ShouldBeFalse)
+			So(timer.Stop(), ShouldBeTrue)
+		})
+		Convey("Ticker 是接口", func() {
+			ticker := c.NewTicker(time.Second)
+			s.Add(time.Second)
+			So(<-ticker.C(), ShouldEqual, now.Add(time.Second))
+			ticker.Stop()
+			So(len(s.Pending()), ShouldEqual, 0)
+		})
+		Convey("AfterFunc 的 C 是 nil", func() {
+			done := make(chan struct{})
+			timer := c.AfterFunc(time.Second, func() { close(done) })
+			So(timer.C(), ShouldBeNil)
+			s.Add(time.Second)
+			<-done
+		})
+		Convey("上下文中的时钟是 *clock.Simulator", func() {
+			ctx, cancel := c.ContextWithTimeout(context.Background(), time.Second)
+			defer cancel()
+			So(clock.Get(ctx), ShouldEqual, s.Simulator)
+			s.Add(time.Second)
+			<-ctx.Done()
+		})
+	})
+}
@@ -0,0 +1,480 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"go/ast"
+	"go/format"
+	"go/token"
+	"go/types"
+	"io/ioutil"
+	"sort"
+	"strconv"
+
+	"github.com/jujili/clock/internal/clockcheck"
+)
+
+// Result 是一个文件的改写结果
+type Result struct {
+	Filename string
+	// Src 是改写后的源代码，只有 Changed 为 true 时才有意义
+	Src     []byte
+	Changed bool
+	// Unfixed 是无法自动改写的地方
+	Unfixed []Unfixed
+}
+
+// Unfixed 是无法自动改写的地方
+type Unfixed struct {
+	Pos    token.Position
+	Func   string
+	Reason string
+}
+
+func (u Unfixed) String() string {
+	return fmt.Sprintf("%s: 无法改写 %s：%s", u.Pos, u.Func, u.Reason)
+}
+
+// edit 把源代码中 [start, end) 的内容替换成 text
+type edit struct {
+	start, end int
+	text       string
+}
+
+// fixFiles 改写属于同一个包的 files
+func fixFiles(fset *token.FileSet, files []*ast.File) ([]Result, error) {
+	pkg, info := clockcheck.Check(fset, files)
+	fields := clockFields(info, files)
+	var results []Result
+	for _, f := range files {
+		r, err := fixFile(fset, pkg, info, fields, f)
+		if err != nil {
+			return nil, err
+		}
+		results = append(results, r)
+	}
+	return results, nil
+}
+
+// fixer 保存改写一个文件时的状态
+type fixer struct {
+	fset   *token.FileSet
+	pkg    *types.Package
+	info   *types.Info
+	fields map[string]string
+	file   *ast.File
+	src    []byte
+	// clockName 是 clock 包在文件中的名称，imported 表示文件已经导入了 clock 包
+	clockName string
+	imported  bool
+	// usedClock 表示改写后的代码使用了 clock 包
+	usedClock bool
+	// rewritten 是改写时被去掉的 time 和 context 包名
+	rewritten map[*ast.Ident]bool
+	edits     []edit
+	unfixed   []Unfixed
+}
+
+func fixFile(fset *token.FileSet, pkg *types.Package, info *types.Info, fields map[string]string, f *ast.File) (Result, error) {
+	tf := fset.File(f.Pos())
+	r := Result{Filename: tf.Name()}
+	src, err := ioutil.ReadFile(tf.Name())
+	if err != nil {
+		return r, err
+	}
+	x := &fixer{
+		fset:      fset,
+		pkg:       pkg,
+		info:      info,
+		fields:    fields,
+		file:      f,
+		src:       src,
+		clockName: "clock",
+		rewritten: make(map[*ast.Ident]bool),
+	}
+	for _, spec := range f.Imports {
+		if p, _ := strconv.Unquote(spec.Path.Value); p == clockcheck.ClockPath {
+			if spec.Name == nil {
+				x.imported = true
+			} else if spec.Name.Name != "_" && spec.Name.Name != "." {
+				x.imported, x.clockName = true, spec.Name.Name
+			}
+		}
+	}
+	ignored := clockcheck.IgnoredLines(fset, f)
+	for _, decl := range f.Decls {
+		recv := x.receiverClock(decl)
+		// 作为函数调用的 selector，已经在 CallExpr 中处理过了
+		called := make(map[*ast.SelectorExpr]bool)
+		// stack 是从 decl 到当前节点的路径
+		var stack []ast.Node
+		ast.Inspect(decl, func(n ast.Node) bool {
+			if n == nil {
+				stack = stack[:len(stack)-1]
+				return true
+			}
+			stack = append(stack, n)
+			var call *ast.CallExpr
+			sel, ok := n.(*ast.SelectorExpr)
+			if c, isCall := n.(*ast.CallExpr); isCall {
+				call = c
+				sel, ok = c.Fun.(*ast.SelectorExpr)
+			}
+			if !ok || called[sel] {
+				return true
+			}
+			name, ok := clockcheck.StdFunc(info, sel)
+			if !ok {
+				return true
+			}
+			called[sel] = true
+			if ignored[fset.Position(sel.Pos()).Line] {
+				return true
+			}
+			x.fix(name, sel, call, recv, stack)
+			return true
+		})
+	}
+	r.Unfixed = x.unfixed
+	if len(x.edits) == 0 {
+		return r, nil
+	}
+	x.fixImports()
+	out, err := format.Source(apply(src, x.edits))
+	if err != nil {
+		return r, fmt.Errorf("%s: %v", tf.Name(), err)
+	}
+	r.Src, r.Changed = out, !bytes.Equal(out, src)
+	return r, nil
+}
+
+// fix 改写一处 time 或 context 函数的使用，stack 是从函数声明到 call 的路径
+func (x *fixer) fix(name string, sel *ast.SelectorExpr, call *ast.CallExpr, recv string, stack []ast.Node) {
+	fn := clockcheck.Replacements[name]
+	fail := func(reason string) {
+		x.unfixed = append(x.unfixed, Unfixed{
+			Pos:    x.fset.Position(sel.Pos()),
+			Func:   name,
+			Reason: reason,
+		})
+	}
+	if call == nil {
+		fail("不是函数调用")
+		return
+	}
+	// 改写后返回的是 *clock.Timer 和 *clock.Ticker，
+	// 被当作 *time.Timer 和 *time.Ticker 使用的话，就无法编译了
+	if clockcheck.ReturnsTimer(name) && x.timerEscapes(call, stack) {
+		fail("结果被当作 *time.Timer 或 *time.Ticker 使用，改写后无法编译")
+		return
+	}
+	// context 包的函数，参数与 clock 包的封装一致
+	if clockcheck.IsContextFunc(name) {
+		if !x.canUseClock(sel.Pos()) {
+			fail(x.clockName + " 已经被其他的标识符占用")
+			return
+		}
+		x.replace(sel, x.clockName+"."+fn, true)
+		return
+	}
+	// 优先使用作用域内的 context.Context 变量
+	if v := clockcheck.ContextInScope(x.pkg, sel.Pos()); v != nil && v.Parent() != x.pkg.Scope() {
+		if !x.canUseClock(sel.Pos()) {
+			fail(x.clockName + " 已经被其他的标识符占用")
+			return
+		}
+		x.replace(sel, x.clockName+"."+fn, true)
+		arg := v.Name()
+		if len(call.Args) > 0 {
+			arg += ", "
+		}
+		x.insert(call.Lparen+1, arg)
+		return
+	}
+	// 其次使用接收者的 clock.Clock 字段
+	if recv != "" {
+		x.replace(sel, recv+"."+fn, false)
+		return
+	}
+	fail("作用域内没有 context.Context，接收者也没有 clock.Clock 字段")
+}
+
+// timerEscapes 报告 call 返回的 *time.Timer 或 *time.Ticker，
+// 是否会被用在需要这个类型的地方，例如结构体的字段，函数的参数和返回值。
+// 只有以下的用法，改写成 *clock.Timer 和 *clock.Ticker 以后依然可以编译：
+//
+//	time.AfterFunc(d, f)
+//	<-time.NewTimer(d).C
+//	t := time.NewTimer(d) // t 只用于 t.C，t.Stop() 和 t.Reset(d)
+func (x *fixer) timerEscapes(call *ast.CallExpr, stack []ast.Node) bool {
+	var expr ast.Expr = call
+	i := len(stack) - 2
+	for ; i >= 0; i-- {
+		paren, ok := stack[i].(*ast.ParenExpr)
+		if !ok {
+			break
+		}
+		expr = paren
+	}
+	if i < 0 {
+		return true
+	}
+	switch p := stack[i].(type) {
+	case *ast.ExprStmt:
+		return false
+	case *ast.SelectorExpr:
+		return p.X != expr
+	case *ast.AssignStmt:
+		if p.Tok != token.DEFINE || len(p.Lhs) != len(p.Rhs) {
+			return true
+		}
+		for j, rhs := range p.Rhs {
+			if rhs == expr {
+				return !x.onlySelected(p.Lhs[j])
+			}
+		}
+	case *ast.ValueSpec:
+		if p.Type != nil || len(p.Names) != len(p.Values) {
+			return true
+		}
+		for j, v := range p.Values {
+			if v == expr {
+				return !x.onlySelected(p.Names[j])
+			}
+		}
+	}
+	return true
+}
+
+// onlySelected 报告 lhs 定义的局部变量，是否只被用于 lhs.Field 和 lhs.Method() 的形式
+func (x *fixer) onlySelected(lhs ast.Expr) bool {
+	id, ok := lhs.(*ast.Ident)
+	if !ok {
+		return false
+	}
+	if id.Name == "_" {
+		return true
+	}
+	// := 中已经定义过的变量，不是新的变量
+	obj := x.info.Defs[id]
+	if obj == nil || x.pkg == nil || obj.Parent() == x.pkg.Scope() {
+		return false
+	}
+	selected := make(map[*ast.Ident]bool)
+	ast.Inspect(x.file, func(n ast.Node) bool {
+		if sel, ok := n.(*ast.SelectorExpr); ok {
+			if base, ok := sel.X.(*ast.Ident); ok {
+				selected[base] = true
+			}
+		}
+		return true
+	})
+	for use, o := range x.info.Uses {
+		if o == obj && !selected[use] {
+			return false
+		}
+	}
+	return true
+}
+
+// canUseClock 报告 pos 处的 x.clockName 是不是指向 clock 包，或者可以导入 clock 包
+func (x *fixer) canUseClock(pos token.Pos) bool {
+	if x.pkg == nil {
+		return false
+	}
+	scope := x.pkg.Scope().Innermost(pos)
+	if scope == nil {
+		scope = x.pkg.Scope()
+	}
+	_, obj := scope.LookupParent(x.clockName, pos)
+	if obj == nil {
+		return !x.imported
+	}
+	pn, ok := obj.(*types.PkgName)
+	return ok && pn.Imported().Path() == clockcheck.ClockPath
+}
+
+// replace 把 sel 替换成 text，usesClock 表示 text 使用了 clock 包
+func (x *fixer) replace(sel *ast.SelectorExpr, text string, usesClock bool) {
+	x.rewritten[sel.X.(*ast.Ident)] = true
+	x.usedClock = x.usedClock || usesClock
+	x.edits = append(x.edits, edit{
+		start: x.offset(sel.Pos()),
+		end:   x.offset(sel.End()),
+		text:  text,
+	})
+}
+
+func (x *fixer) insert(pos token.Pos, text string) {
+	off := x.offset(pos)
+	x.edits = append(x.edits, edit{start: off, end: off, text: text})
+}
+
+func (x *fixer) offset(pos token.Pos) int {
+	return x.fset.Position(pos).Offset
+}
+
+// receiverClock 返回方法 decl 的接收者中 clock.Clock 字段的表达式，例如 s.clock
+func (x *fixer) receiverClock(decl ast.Decl) string {
+	fd, ok := decl.(*ast.FuncDecl)
+	if !ok || fd.Recv == nil || len(fd.Recv.List) != 1 {
+		return ""
+	}
+	field := fd.Recv.List[0]
+	if len(field.Names) != 1 || field.Names[0].Name == "_" {
+		return ""
+	}
+	typ := field.Type
+	if star, ok := typ.(*ast.StarExpr); ok {
+		typ = star.X
+	}
+	id, ok := typ.(*ast.Ident)
+	if !ok {
+		return ""
+	}
+	name, ok := x.fields[id.Name]
+	if !ok {
+		return ""
+	}
+	return field.Names[0].Name + "." + name
+}
+
+// clockFields 返回包中含有 clock.Clock 字段的结构体，类型名 -> 字段名
+func clockFields(info *types.Info, files []*ast.File) map[string]string {
+	fields := make(map[string]string)
+	for _, f := range files {
+		ast.Inspect(f, func(n ast.Node) bool {
+			spec, ok := n.(*ast.TypeSpec)
+			if !ok {
+				return true
+			}
+			st, ok := spec.Type.(*ast.StructType)
+			if !ok {
+				return false
+			}
+			for _, field := range st.Fields.List {
+				if !isClockType(info, field.Type) {
+					continue
+				}
+				// 嵌入的字段，名称就是类型名
+				name := "Clock"
+				if len(field.Names) > 0 {
+					name = field.Names[0].Name
+				}
+				if name != "_" {
+					fields[spec.Name.Name] = name
+					break
+				}
+			}
+			return false
+		})
+	}
+	return fields
+}
+
+func isClockType(info *types.Info, expr ast.Expr) bool {
+	sel, ok := expr.(*ast.SelectorExpr)
+	if !ok || sel.Sel.Name != "Clock" {
+		return false
+	}
+	x, ok := sel.X.(*ast.Ident)
+	if !ok {
+		return false
+	}
+	pn, ok := info.Uses[x].(*types.PkgName)
+	return ok && pn.Imported().Path() == clockcheck.ClockPath
+}
+
+// fixImports 导入 clock 包，并删除不再使用的 time 和 context 包
+func (x *fixer) fixImports() {
+	// 统计改写后，还在使用的包
+	used := make(map[string]bool)
+	ast.Inspect(x.file, func(n ast.Node) bool {
+		id, ok := n.(*ast.Ident)
+		if !ok || x.rewritten[id] {
+			return true
+		}
+		if pn, ok := x.info.Uses[id].(*types.PkgName); ok {
+			used[pn.Imported().Path()] = true
+		}
+		return true
+	})
+	removed := make(map[*ast.ImportSpec]bool)
+	for _, spec := range x.file.Imports {
+		p, _ := strconv.Unquote(spec.Path.Value)
+		if (p == "time" || p == "context") && !used[p] &&
+			(spec.Name == nil || (spec.Name.Name != "_" && spec.Name.Name != ".")) {
+			removed[spec] = true
+		}
+	}
+	var decls []*ast.GenDecl
+	for _, decl := range x.file.Decls {
+		if gd, ok := decl.(*ast.GenDecl); ok && gd.Tok == token.IMPORT {
+			decls = append(decls, gd)
+		}
+	}
+	added := !x.usedClock || x.imported
+	for _, gd := range decls {
+		all := true
+		for _, spec := range gd.Specs {
+			all = all && removed[spec.(*ast.ImportSpec)]
+		}
+		if all {
+			x.edits = append(x.edits, x.lineEdit(gd.Pos(), gd.End()))
+			continue
+		}
+		for _, spec := range gd.Specs {
+			if removed[spec.(*ast.ImportSpec)] {
+				x.edits = append(x.edits, x.lineEdit(spec.Pos(), spec.End()))
+			}
+		}
+		if added {
+			continue
+		}
+		// 放在第一个 import 的最后，单独一组
+		clockSpec := strconv.Quote(clockcheck.ClockPath)
+		if gd.Lparen.IsValid() {
+			x.insert(gd.Rparen, "\n\t"+clockSpec+"\n")
+		} else {
+			spec := gd.Specs[0]
+			text := string(x.src[x.offset(spec.Pos()):x.offset(spec.End())])
+			x.edits = append(x.edits, edit{
+				start: x.offset(gd.Pos()),
+				end:   x.offset(gd.End()),
+				text:  "import (\n\t" + text + "\n\n\t" + clockSpec + "\n)",
+			})
+		}
+		added = true
+	}
+	if !added {
+		x.insert(decls[0].Pos(), "import "+strconv.Quote(clockcheck.ClockPath)+"\n\n")
+	}
+}
+
+// lineEdit 删除 [pos, end) 所在的整行
+func (x *fixer) lineEdit(pos, end token.Pos) edit {
+	tf := x.fset.File(pos)
+	start := tf.Offset(tf.LineStart(tf.Line(pos)))
+	stop := tf.Size()
+	if line := tf.Line(end); line < tf.LineCount() {
+		stop = tf.Offset(tf.LineStart(line + 1))
+	}
+	return edit{start: start, end: stop}
+}
+
+// apply 把 edits 应用到 src 上。
+// 从后往前应用，这样前面的偏移不会改变。
+// 同一位置的替换先于插入，这样插入的内容会在替换的内容之前。
+func apply(src []byte, edits []edit) []byte {
+	sort.SliceStable(edits, func(i, j int) bool {
+		if edits[i].start != edits[j].start {
+			return edits[i].start > edits[j].start
+		}
+		return edits[i].end > edits[j].end
+	})
+	out := append([]byte(nil), src...)
+	for _, e := range edits {
+		out = append(out[:e.start], append([]byte(e.text), out[e.end:]...)...)
+	}
+	return out
+}
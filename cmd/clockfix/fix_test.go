@@ -0,0 +1,95 @@
+package main
+
+import (
+	"go/token"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/jujili/clock/internal/clockcheck"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func Test_fixFiles(t *testing.T) {
+	Convey("改写 testdata/a", t, func() {
+		fset := token.NewFileSet()
+		pkgs, err := clockcheck.LoadDir(fset, filepath.Join("testdata", "a"), false, nil)
+		So(err, ShouldBeNil)
+		So(len(pkgs), ShouldEqual, 1)
+		results, err := fixFiles(fset, pkgs[0])
+		So(err, ShouldBeNil)
+		So(len(results), ShouldEqual, 6)
+		Convey("改写的结果与 testdata/golden 一致", func() {
+			for _, r := range results {
+				So(r.Changed, ShouldBeTrue)
+				name := filepath.Base(r.Filename)
+				want, err := ioutil.ReadFile(filepath.Join("testdata", "golden", name+".golden"))
+				So(err, ShouldBeNil)
+				So(string(r.Src), ShouldEqual, string(want))
+			}
+		})
+		Convey("报告无法改写的地方", func() {
+			var unfixed []string
+			for _, r := range results {
+				for _, u := range r.Unfixed {
+					unfixed = append(unfixed, filepath.Base(u.Pos.Filename)+":"+u.Func)
+					So(u.String(), ShouldContainSubstring, "无法改写 "+u.Func)
+				}
+			}
+			So(unfixed, ShouldResemble, []string{
+				"a.go:time.Now",
+				"server.go:time.Now",
+				"timer.go:time.NewTimer",
+				"timer.go:time.NewTicker",
+				"timer.go:time.AfterFunc",
+				"timer.go:time.NewTimer",
+			})
+		})
+	})
+}
+
+func Test_fixFiles_conflict(t *testing.T) {
+	Convey("clock 已经被其他的标识符占用", t, func() {
+		dir, err := ioutil.TempDir("", "clockfix")
+		So(err, ShouldBeNil)
+		defer os.RemoveAll(dir)
+		src := strings.Join([]string{
+			"package b",
+			"",
+			"import (",
+			`	"context"`,
+			`	"time"`,
+			")",
+			"",
+			"func f(ctx context.Context) {",
+			"	clock := 1",
+			"	_ = clock",
+			"	time.Sleep(time.Second)",
+			"}",
+			"",
+		}, "\n")
+		So(ioutil.WriteFile(filepath.Join(dir, "b.go"), []byte(src), 0644), ShouldBeNil)
+		fset := token.NewFileSet()
+		pkgs, err := clockcheck.LoadDir(fset, dir, false, nil)
+		So(err, ShouldBeNil)
+		results, err := fixFiles(fset, pkgs[0])
+		So(err, ShouldBeNil)
+		So(results[0].Changed, ShouldBeFalse)
+		So(len(results[0].Unfixed), ShouldEqual, 1)
+		So(results[0].Unfixed[0].Reason, ShouldEqual, "clock 已经被其他的标识符占用")
+	})
+}
+
+func Test_apply(t *testing.T) {
+	Convey("同一位置的替换先于插入", t, func() {
+		src := []byte("f(g(x))")
+		out := apply(src, []edit{
+			{start: 2, end: 2, text: "ctx, "},
+			{start: 2, end: 3, text: "h"},
+			{start: 0, end: 1, text: "k"},
+		})
+		So(string(out), ShouldEqual, "k(ctx, h(x))")
+	})
+}
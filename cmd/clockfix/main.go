@@ -0,0 +1,88 @@
+// clockfix 把直接使用 time 和 context 标准库的代码，改写成使用 clock 包
+//
+//	time.Sleep(d)               -> clock.Sleep(ctx, d)
+//	context.WithTimeout(ctx, d) -> clock.ContextWithTimeout(ctx, d)
+//	time.Now()                  -> s.clock.Now()
+//
+// 作用域内有 context.Context 变量时，使用 clock.Now(ctx) 这样的封装；
+// 否则，如果方法的接收者有 clock.Clock 字段，就使用这个字段。
+// 两者都没有的地方，会输出到标准错误，需要手动修改。
+// 改写后，会导入 clock 包，删除不再使用的 time 和 context 包，并用 go/format 格式化。
+//
+// 用法
+//
+//	clockfix [-w] [-l] [-tests] [目录 | 目录/...]
+//
+// 与 clocklint 一样，带有 //clocklint:ignore 注释的行不会被改写。
+package main
+
+import (
+	"flag"
+	"fmt"
+	"go/token"
+	"io"
+	"io/ioutil"
+	"os"
+
+	"github.com/jujili/clock/internal/clockcheck"
+)
+
+func main() {
+	os.Exit(run(os.Args[1:], os.Stdout, os.Stderr))
+}
+
+func run(args []string, stdout, stderr io.Writer) int {
+	fs := flag.NewFlagSet("clockfix", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	write := fs.Bool("w", false, "把结果写回源文件，而不是输出到标准输出")
+	list := fs.Bool("l", false, "只列出需要改写的文件")
+	tests := fs.Bool("tests", false, "同时改写 _test.go 文件")
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+	patterns := fs.Args()
+	if len(patterns) == 0 {
+		patterns = []string{"."}
+	}
+	dirs, err := clockcheck.Expand(patterns)
+	if err != nil {
+		fmt.Fprintln(stderr, "clockfix:", err)
+		return 2
+	}
+	fset := token.NewFileSet()
+	for _, dir := range dirs {
+		pkgs, err := clockcheck.LoadDir(fset, dir, *tests, nil)
+		if err != nil {
+			fmt.Fprintln(stderr, "clockfix:", err)
+			return 2
+		}
+		for _, files := range pkgs {
+			results, err := fixFiles(fset, files)
+			if err != nil {
+				fmt.Fprintln(stderr, "clockfix:", err)
+				return 2
+			}
+			for _, r := range results {
+				for _, u := range r.Unfixed {
+					fmt.Fprintln(stderr, u)
+				}
+				if !r.Changed {
+					continue
+				}
+				if *list {
+					fmt.Fprintln(stdout, r.Filename)
+				}
+				if *write {
+					if err := ioutil.WriteFile(r.Filename, r.Src, 0644); err != nil {
+						fmt.Fprintln(stderr, "clockfix:", err)
+						return 2
+					}
+				}
+				if !*list && !*write {
+					stdout.Write(r.Src)
+				}
+			}
+		}
+	}
+	return 0
+}
@@ -0,0 +1,47 @@
+package main
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func Test_run(t *testing.T) {
+	Convey("运行 clockfix", t, func() {
+		var stdout, stderr bytes.Buffer
+		Convey("默认输出改写后的文件", func() {
+			code := run([]string{"testdata/a"}, &stdout, &stderr)
+			So(code, ShouldEqual, 0)
+			So(stdout.String(), ShouldContainSubstring, "clock.Sleep(ctx, d)")
+			So(stderr.String(), ShouldContainSubstring, "无法改写 time.Now")
+		})
+		Convey("-l 只列出文件", func() {
+			code := run([]string{"-l", "testdata/..."}, &stdout, &stderr)
+			So(code, ShouldEqual, 0)
+			So(stdout.String(), ShouldContainSubstring, filepath.Join("testdata", "a", "server.go")+"\n")
+			So(stdout.String(), ShouldNotContainSubstring, "package")
+		})
+		Convey("-w 写回源文件", func() {
+			dir, err := ioutil.TempDir("", "clockfix")
+			So(err, ShouldBeNil)
+			defer os.RemoveAll(dir)
+			src, _ := ioutil.ReadFile(filepath.Join("testdata", "a", "remove.go"))
+			name := filepath.Join(dir, "remove.go")
+			So(ioutil.WriteFile(name, src, 0644), ShouldBeNil)
+			code := run([]string{"-w", dir}, &stdout, &stderr)
+			So(code, ShouldEqual, 0)
+			So(stdout.String(), ShouldBeEmpty)
+			got, _ := ioutil.ReadFile(name)
+			want, _ := ioutil.ReadFile(filepath.Join("testdata", "golden", "remove.go.golden"))
+			So(string(got), ShouldEqual, string(want))
+		})
+		Convey("目录不存在，退出码为 2", func() {
+			code := run([]string{"testdata/nothing"}, &stdout, &stderr)
+			So(code, ShouldEqual, 2)
+		})
+	})
+}
@@ -0,0 +1,26 @@
+package a
+
+import (
+	"context"
+	"time"
+)
+
+// wait 等待 d
+func wait(ctx context.Context, d time.Duration) {
+	time.Sleep(d)
+	ctx, cancel := context.WithTimeout(ctx, d)
+	defer cancel()
+	<-ctx.Done()
+}
+
+func since(ctx context.Context, start time.Time) time.Duration {
+	return time.Since(time.Now().Add(time.Until(start)))
+}
+
+func nothing() time.Time {
+	return time.Now()
+}
+
+func ignored(ctx context.Context) time.Time {
+	return time.Now() //clocklint:ignore
+}
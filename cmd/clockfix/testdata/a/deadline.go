@@ -0,0 +1,12 @@
+package a
+
+import (
+	"context"
+	stdtime "time"
+)
+
+var deadline stdtime.Time
+
+func sleep(ctx context.Context) {
+	stdtime.Sleep(stdtime.Second)
+}
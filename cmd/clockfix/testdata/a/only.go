@@ -0,0 +1,8 @@
+package a
+
+import "context"
+
+func parent(ctx context.Context) {
+	_, cancel := context.WithDeadline(ctx, deadline)
+	cancel()
+}
@@ -0,0 +1,11 @@
+package a
+
+import (
+	"context"
+	"time"
+)
+
+func pause(ctx context.Context) {
+	// 只有这里使用了 time 包
+	time.Sleep(1)
+}
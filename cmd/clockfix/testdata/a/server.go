@@ -0,0 +1,26 @@
+package a
+
+import (
+	"time"
+
+	"github.com/jujili/clock"
+)
+
+type server struct {
+	name  string
+	clock clock.Clock
+}
+
+func (s *server) deadline() time.Time {
+	return time.Now().Add(time.Minute)
+}
+
+func (s *server) tick() {
+	time.Sleep(time.Second)
+}
+
+type plain struct{}
+
+func (p plain) now() time.Time {
+	return time.Now()
+}
@@ -0,0 +1,33 @@
+package a
+
+import (
+	"context"
+	"time"
+)
+
+type poller struct {
+	timer  *time.Timer
+	ticker *time.Ticker
+}
+
+func (p *poller) start(ctx context.Context, d time.Duration) {
+	p.timer = time.NewTimer(d)
+	p.ticker = time.NewTicker(d)
+}
+
+func newTimer(ctx context.Context, d time.Duration) *time.Timer {
+	return time.AfterFunc(d, func() {})
+}
+
+func keep(ctx context.Context, d time.Duration) []*time.Timer {
+	t := time.NewTimer(d)
+	return []*time.Timer{t}
+}
+
+func waitTimer(ctx context.Context, d time.Duration) {
+	t := time.NewTimer(d)
+	defer t.Stop()
+	<-t.C
+	<-(time.NewTimer(d)).C
+	time.AfterFunc(d, func() {})
+}
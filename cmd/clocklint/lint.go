@@ -0,0 +1,166 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"go/ast"
+	"go/printer"
+	"go/token"
+	"go/types"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/jujili/clock/internal/clockcheck"
+)
+
+// Config 是 linter 的配置
+type Config struct {
+	// Allow 中的函数不会被报告，例如 time.Since
+	Allow map[string]bool
+	// Exclude 中的路径不会被检查。
+	// 以 /... 结尾的表示目录下的所有文件，其余的使用 path.Match 匹配。
+	Exclude []string
+	// Tests 为 true 时，也检查 _test.go 文件
+	Tests bool
+}
+
+// Issue 是发现的一个问题
+type Issue struct {
+	Pos     token.Position
+	Func    string
+	Suggest string
+}
+
+func (i Issue) String() string {
+	return fmt.Sprintf("%s: 直接使用了 %s，请使用 %s", i.Pos, i.Func, i.Suggest)
+}
+
+func (cfg *Config) excluded(name string) bool {
+	name = filepath.ToSlash(filepath.Clean(name))
+	for _, pattern := range cfg.Exclude {
+		pattern = filepath.ToSlash(pattern)
+		if strings.HasSuffix(pattern, "/...") {
+			dir := path.Clean(strings.TrimSuffix(pattern, "/..."))
+			if dir == "." || name == dir || strings.HasPrefix(name, dir+"/") {
+				return true
+			}
+			continue
+		}
+		pattern = path.Clean(pattern)
+		if ok, _ := path.Match(pattern, name); ok {
+			return true
+		}
+		if ok, _ := path.Match(pattern, path.Dir(name)); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// lintDir 检查目录 dir 中的 Go 包
+func lintDir(fset *token.FileSet, dir string, cfg *Config) ([]Issue, error) {
+	pkgs, err := clockcheck.LoadDir(fset, dir, cfg.Tests, cfg.excluded)
+	if err != nil {
+		return nil, err
+	}
+	var issues []Issue
+	for _, files := range pkgs {
+		issues = append(issues, lintFiles(fset, files, cfg)...)
+	}
+	return issues, nil
+}
+
+// lintFiles 检查属于同一个包的 files
+func lintFiles(fset *token.FileSet, files []*ast.File, cfg *Config) []Issue {
+	pkg, info := clockcheck.Check(fset, files)
+	var issues []Issue
+	for _, f := range files {
+		ignored := clockcheck.IgnoredLines(fset, f)
+		// 作为函数调用的 selector，已经在 CallExpr 中处理过了
+		called := make(map[*ast.SelectorExpr]bool)
+		ast.Inspect(f, func(n ast.Node) bool {
+			var call *ast.CallExpr
+			sel, ok := n.(*ast.SelectorExpr)
+			if c, isCall := n.(*ast.CallExpr); isCall {
+				call = c
+				sel, ok = c.Fun.(*ast.SelectorExpr)
+			}
+			if !ok || called[sel] {
+				return true
+			}
+			name, ok := clockcheck.StdFunc(info, sel)
+			if !ok {
+				return true
+			}
+			called[sel] = true
+			pos := fset.Position(sel.Pos())
+			if cfg.Allow[name] || ignored[pos.Line] {
+				return true
+			}
+			issues = append(issues, Issue{
+				Pos:     pos,
+				Func:    name,
+				Suggest: suggest(fset, name, call, contextName(pkg, sel.Pos())),
+			})
+			return true
+		})
+	}
+	return issues
+}
+
+// suggest 返回替换的建议。
+// call 不为 nil 时，会给出替换后的调用语句。
+// ctx 是作用域内 context.Context 变量的名称，为空表示没有。
+func suggest(fset *token.FileSet, name string, call *ast.CallExpr, ctx string) string {
+	fn := clockcheck.Replacements[name]
+	// 只是引用了函数，而不是调用
+	if call == nil {
+		if clockcheck.IsContextFunc(name) {
+			return "clock." + fn
+		}
+		return fmt.Sprintf("c.%s，c 是注入的 clock.Clock", fn)
+	}
+	var args []string
+	for _, arg := range call.Args {
+		args = append(args, render(fset, arg))
+	}
+	// context 包的函数，参数与 clock 包的封装一致
+	if clockcheck.IsContextFunc(name) {
+		return fmt.Sprintf("clock.%s(%s)", fn, strings.Join(args, ", "))
+	}
+	if ctx != "" {
+		args = append([]string{ctx}, args...)
+		return fmt.Sprintf("clock.%s(%s)", fn, strings.Join(args, ", "))
+	}
+	return fmt.Sprintf("c.%s(%s)，c 是注入的 clock.Clock", fn, strings.Join(args, ", "))
+}
+
+// contextName 返回作用域内 context.Context 变量的名称
+func contextName(pkg *types.Package, pos token.Pos) string {
+	if v := clockcheck.ContextInScope(pkg, pos); v != nil {
+		return v.Name()
+	}
+	return ""
+}
+
+func render(fset *token.FileSet, n ast.Node) string {
+	var buf bytes.Buffer
+	printer.Fprint(&buf, fset, n)
+	return buf.String()
+}
+
+// sortIssues 按照位置排序
+func sortIssues(issues []Issue) {
+	sort.Slice(issues, func(i, j int) bool {
+		a, b := issues[i].Pos, issues[j].Pos
+		if a.Filename != b.Filename {
+			return a.Filename < b.Filename
+		}
+		if a.Line != b.Line {
+			return a.Line < b.Line
+		}
+		return a.Column < b.Column
+	})
+}
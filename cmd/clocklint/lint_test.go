@@ -0,0 +1,86 @@
+package main
+
+import (
+	"go/token"
+	"path/filepath"
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+// lines 把 issues 整理成 行号 -> 函数名
+func lines(issues []Issue) map[int]string {
+	m := make(map[int]string)
+	for _, issue := range issues {
+		m[issue.Pos.Line] = issue.Func
+	}
+	return m
+}
+
+func Test_lintDir(t *testing.T) {
+	Convey("检查 testdata/src/a", t, func() {
+		dir := filepath.Join("testdata", "src", "a")
+		fset := token.NewFileSet()
+		cfg := &Config{Allow: map[string]bool{}}
+		Convey("报告直接使用 time 和 context 的地方", func() {
+			issues, err := lintDir(fset, dir, cfg)
+			So(err, ShouldBeNil)
+			So(lines(issues), ShouldResemble, map[int]string{
+				11: "time.Now",
+				12: "time.Sleep",
+				13: "time.After",
+				14: "context.WithTimeout",
+				16: "time.Since",
+				20: "time.Now",
+			})
+		})
+		Convey("给出替换后的调用", func() {
+			issues, _ := lintDir(fset, dir, cfg)
+			sortIssues(issues)
+			So(issues[0].Suggest, ShouldEqual, "clock.Now(ctx)")
+			So(issues[1].Suggest, ShouldEqual, "clock.Sleep(ctx, stdtime.Second)")
+			So(issues[3].Suggest, ShouldEqual, "clock.ContextWithTimeout(ctx, stdtime.Second)")
+			So(issues[5].Suggest, ShouldEqual, "c.Now，c 是注入的 clock.Clock")
+			So(issues[0].String(), ShouldEndWith, "直接使用了 time.Now，请使用 clock.Now(ctx)")
+		})
+		Convey("Allow 中的函数不会被报告", func() {
+			cfg.Allow["time.Now"] = true
+			issues, err := lintDir(fset, dir, cfg)
+			So(err, ShouldBeNil)
+			So(len(issues), ShouldEqual, 4)
+		})
+		Convey("Tests 为 true 时，检查测试文件", func() {
+			cfg.Tests = true
+			issues, err := lintDir(fset, dir, cfg)
+			So(err, ShouldBeNil)
+			So(len(issues), ShouldEqual, 7)
+			last := issues[len(issues)-1]
+			So(filepath.Base(last.Pos.Filename), ShouldEqual, "a_test.go")
+			So(last.Suggest, ShouldEqual, "c.Sleep(time.Millisecond)，c 是注入的 clock.Clock")
+		})
+		Convey("Exclude 中的文件不会被检查", func() {
+			cfg.Exclude = []string{"testdata/src/a/a.go"}
+			issues, err := lintDir(fset, dir, cfg)
+			So(err, ShouldBeNil)
+			So(issues, ShouldBeEmpty)
+		})
+		Convey("没有 Go 文件的目录", func() {
+			issues, err := lintDir(fset, "testdata", cfg)
+			So(err, ShouldBeNil)
+			So(issues, ShouldBeEmpty)
+		})
+	})
+}
+
+func Test_Config_excluded(t *testing.T) {
+	Convey("Exclude 的匹配规则", t, func() {
+		cfg := &Config{Exclude: []string{"internal/...", "*_gen.go", "cmd/*"}}
+		So(cfg.excluded("internal/real/real.go"), ShouldBeTrue)
+		So(cfg.excluded("internal"), ShouldBeTrue)
+		So(cfg.excluded("internalx/a.go"), ShouldBeFalse)
+		So(cfg.excluded("mock_gen.go"), ShouldBeTrue)
+		So(cfg.excluded("cmd/main.go"), ShouldBeTrue)
+		So(cfg.excluded("pkg/a.go"), ShouldBeFalse)
+		So((&Config{Exclude: []string{"./..."}}).excluded("a/b.go"), ShouldBeTrue)
+	})
+}
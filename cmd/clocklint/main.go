@@ -0,0 +1,86 @@
+// clocklint 报告直接使用 time 和 context 标准库中，依赖当前时间的函数的地方，
+// 这些地方应该使用 clock.Clock 或者 clock.Now(ctx) 这样的封装，
+// 才能在测试中被 Simulator 控制。
+//
+// 用法
+//
+//	clocklint [flags] [目录 | 目录/...]
+//
+// 不需要检查的行，可以在行尾或上一行添加 //clocklint:ignore 注释。
+// 发现问题时，退出码为 1；出错时，退出码为 2。
+package main
+
+import (
+	"flag"
+	"fmt"
+	"go/token"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/jujili/clock/internal/clockcheck"
+)
+
+func main() {
+	os.Exit(run(os.Args[1:], os.Stdout, os.Stderr))
+}
+
+func run(args []string, stdout, stderr io.Writer) int {
+	fs := flag.NewFlagSet("clocklint", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	allow := fs.String("allow", "", "不需要报告的函数，以逗号分隔，例如 time.Since,time.Until")
+	exclude := fs.String("exclude", "", "不需要检查的路径，以逗号分隔，例如 internal/realtime/...,*_gen.go")
+	tests := fs.Bool("tests", false, "同时检查 _test.go 文件")
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+	cfg := &Config{
+		Allow:   make(map[string]bool),
+		Exclude: splitList(*exclude),
+		Tests:   *tests,
+	}
+	for _, name := range splitList(*allow) {
+		if _, ok := clockcheck.Replacements[name]; !ok {
+			fmt.Fprintf(stderr, "clocklint: 未知的函数 %s\n", name)
+			return 2
+		}
+		cfg.Allow[name] = true
+	}
+	patterns := fs.Args()
+	if len(patterns) == 0 {
+		patterns = []string{"."}
+	}
+	dirs, err := clockcheck.Expand(patterns)
+	if err != nil {
+		fmt.Fprintln(stderr, "clocklint:", err)
+		return 2
+	}
+	fset := token.NewFileSet()
+	var issues []Issue
+	for _, dir := range dirs {
+		found, err := lintDir(fset, dir, cfg)
+		if err != nil {
+			fmt.Fprintln(stderr, "clocklint:", err)
+			return 2
+		}
+		issues = append(issues, found...)
+	}
+	sortIssues(issues)
+	for _, issue := range issues {
+		fmt.Fprintln(stdout, issue)
+	}
+	if len(issues) > 0 {
+		return 1
+	}
+	return 0
+}
+
+func splitList(s string) []string {
+	var list []string
+	for _, item := range strings.Split(s, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			list = append(list, item)
+		}
+	}
+	return list
+}
@@ -0,0 +1,42 @@
+package main
+
+import (
+	"bytes"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func Test_run(t *testing.T) {
+	Convey("运行 clocklint", t, func() {
+		var stdout, stderr bytes.Buffer
+		Convey("发现问题时，退出码为 1", func() {
+			code := run([]string{"testdata/src/..."}, &stdout, &stderr)
+			So(code, ShouldEqual, 1)
+			out := strings.Split(strings.TrimSpace(stdout.String()), "\n")
+			So(len(out), ShouldEqual, 7)
+			So(out[6], ShouldStartWith, filepath.Join("testdata", "src", "b", "b.go")+":6:9:")
+		})
+		Convey("没有问题时，退出码为 0", func() {
+			code := run([]string{"-allow", "time.Now", "testdata/src/b"}, &stdout, &stderr)
+			So(code, ShouldEqual, 0)
+			So(stdout.String(), ShouldBeEmpty)
+		})
+		Convey("-exclude", func() {
+			code := run([]string{"-exclude", "testdata/src/a/...", "testdata/..."}, &stdout, &stderr)
+			So(code, ShouldEqual, 1)
+			So(strings.Count(stdout.String(), "\n"), ShouldEqual, 1)
+		})
+		Convey("未知的函数，退出码为 2", func() {
+			code := run([]string{"-allow", "time.Parse", "testdata/src/b"}, &stdout, &stderr)
+			So(code, ShouldEqual, 2)
+			So(stderr.String(), ShouldContainSubstring, "time.Parse")
+		})
+		Convey("目录不存在，退出码为 2", func() {
+			code := run([]string{"testdata/nothing/..."}, &stdout, &stderr)
+			So(code, ShouldEqual, 2)
+		})
+	})
+}
@@ -0,0 +1,45 @@
+package a
+
+import (
+	"context"
+	stdtime "time"
+
+	"github.com/jujili/clock"
+)
+
+func direct(ctx context.Context) {
+	_ = stdtime.Now()
+	stdtime.Sleep(stdtime.Second)
+	<-stdtime.After(stdtime.Second)
+	ctx, cancel := context.WithTimeout(ctx, stdtime.Second)
+	defer cancel()
+	_ = stdtime.Since(stdtime.Time{})
+}
+
+func reference() func() stdtime.Time {
+	return stdtime.Now
+}
+
+func methods(t stdtime.Time) bool {
+	// time.Time 的方法不需要报告
+	return t.After(t.Add(stdtime.Second))
+}
+
+func wrapped(ctx context.Context) {
+	_ = clock.Now(ctx)
+}
+
+func ignored() {
+	_ = stdtime.Now() //clocklint:ignore
+	//clocklint:ignore 启动时间使用真实时间
+	_ = stdtime.Now()
+}
+
+type shadow struct{}
+
+func (shadow) Now() int { return 0 }
+
+func shadowed() {
+	stdtime := shadow{}
+	_ = stdtime.Now()
+}
@@ -0,0 +1,7 @@
+package a
+
+import "time"
+
+func helper() {
+	time.Sleep(time.Millisecond)
+}
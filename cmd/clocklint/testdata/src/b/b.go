@@ -0,0 +1,7 @@
+package b
+
+import "time"
+
+func Deadline() time.Time {
+	return time.Now().Add(time.Minute)
+}
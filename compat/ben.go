@@ -0,0 +1,123 @@
+package compat
+
+import (
+	"context"
+	"time"
+
+	"github.com/jujili/clock"
+)
+
+// BenClock 是 github.com/benbjohnson/clock.Clock 的方法形状，
+// 只是 Timer 和 Ticker 换成了本包的 *BenTimer 和 *BenTicker。
+type BenClock interface {
+	After(d time.Duration) <-chan time.Time
+	AfterFunc(d time.Duration, f func()) *BenTimer
+	Now() time.Time
+	Since(t time.Time) time.Duration
+	Until(t time.Time) time.Duration
+	Sleep(d time.Duration)
+	Tick(d time.Duration) <-chan time.Time
+	Ticker(d time.Duration) *BenTicker
+	Timer(d time.Duration) *BenTimer
+	WithDeadline(parent context.Context, d time.Time) (context.Context, context.CancelFunc)
+	WithTimeout(parent context.Context, t time.Duration) (context.Context, context.CancelFunc)
+}
+
+// BenTimer 与 benbjohnson/clock.Timer 的形状一样
+type BenTimer struct {
+	C <-chan time.Time
+	t *clock.Timer
+}
+
+// Stop prevents the Timer from firing.
+func (t *BenTimer) Stop() bool {
+	return t.t.Stop()
+}
+
+// Reset changes the timer to expire after duration d.
+func (t *BenTimer) Reset(d time.Duration) bool {
+	return t.t.Reset(d)
+}
+
+// BenTicker 与 benbjohnson/clock.Ticker 的形状一样
+// NOTICE: clock.Ticker 不支持 Reset，所以 BenTicker 也没有 Reset 方法
+type BenTicker struct {
+	C <-chan time.Time
+	t *clock.Ticker
+}
+
+// Stop turns off the ticker.
+func (t *BenTicker) Stop() {
+	t.t.Stop()
+}
+
+// Ben 用 clock.Clock 实现了 BenClock
+type Ben struct {
+	c clock.Clock
+}
+
+// NewBen 返回基于 c 的 *Ben
+func NewBen(c clock.Clock) *Ben {
+	return &Ben{c: c}
+}
+
+// After waits for the duration to elapse and then sends the current time on
+// the returned channel.
+func (b *Ben) After(d time.Duration) <-chan time.Time {
+	return b.c.After(d)
+}
+
+// AfterFunc waits for the duration to elapse and then calls f in its own goroutine.
+func (b *Ben) AfterFunc(d time.Duration, f func()) *BenTimer {
+	return &BenTimer{t: b.c.AfterFunc(d, f)}
+}
+
+// Now returns the current time.
+func (b *Ben) Now() time.Time {
+	return b.c.Now()
+}
+
+// Since returns the time elapsed since t.
+func (b *Ben) Since(t time.Time) time.Duration {
+	return b.c.Since(t)
+}
+
+// Until returns the duration until t.
+func (b *Ben) Until(t time.Time) time.Duration {
+	return b.c.Until(t)
+}
+
+// Sleep pauses the current goroutine for at least the duration d.
+func (b *Ben) Sleep(d time.Duration) {
+	b.c.Sleep(d)
+}
+
+// Tick is a convenience wrapper for Ticker providing access to the ticking
+// channel only.
+func (b *Ben) Tick(d time.Duration) <-chan time.Time {
+	return b.c.Tick(d)
+}
+
+// Ticker returns a new Ticker containing a channel that will send the
+// current time with a period specified by the duration d.
+func (b *Ben) Ticker(d time.Duration) *BenTicker {
+	t := b.c.NewTicker(d)
+	return &BenTicker{C: t.C, t: t}
+}
+
+// Timer creates a new Timer that will send the current time on its channel
+// after at least duration d.
+func (b *Ben) Timer(d time.Duration) *BenTimer {
+	t := b.c.NewTimer(d)
+	return &BenTimer{C: t.C, t: t}
+}
+
+// WithDeadline 与 context.WithDeadline 一样，只是基于 b 的时间线
+func (b *Ben) WithDeadline(parent context.Context, d time.Time) (context.Context, context.CancelFunc) {
+	return b.c.ContextWithDeadline(parent, d)
+}
+
+// WithTimeout 与 context.WithTimeout 一样，只是基于 b 的时间线
+func (b *Ben) WithTimeout(parent context.Context, t time.Duration) (context.Context, context.CancelFunc) {
+	return b.c.ContextWithTimeout(parent, t)
+}
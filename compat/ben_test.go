@@ -0,0 +1,57 @@
+package compat
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/jujili/clock"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+var _ BenClock = (*Ben)(nil)
+
+func Test_Ben(t *testing.T) {
+	Convey("用 Simulator 驱动 benbjohnson 风格的时钟", t, func() {
+		now := time.Date(2020, 5, 20, 0, 0, 0, 0, time.UTC)
+		s := clock.NewSimulator(now)
+		var b BenClock = NewBen(s)
+		So(b.Now(), ShouldEqual, now)
+		So(b.Until(now.Add(time.Second)), ShouldEqual, time.Second)
+		Convey("Timer 和 Ticker", func() {
+			timer := b.Timer(time.Second)
+			ticker := b.Ticker(time.Second)
+			defer ticker.Stop()
+			s.Add(time.Second)
+			So(<-timer.C, ShouldEqual, now.Add(time.Second))
+			So(<-ticker.C, ShouldEqual, now.Add(time.Second))
+			So(timer.Reset(time.Second), ShouldBeFalse)
+			So(timer.Stop(), ShouldBeTrue)
+		})
+		Convey("AfterFunc", func() {
+			done := make(chan struct{})
+			timer := b.AfterFunc(time.Second, func() { close(done) })
+			So(timer.C, ShouldBeNil)
+			s.Add(time.Second)
+			<-done
+			So(timer.Stop(), ShouldBeFalse)
+		})
+		Convey("上下文", func() {
+			ctx, cancel := b.WithTimeout(context.Background(), time.Second)
+			defer cancel()
+			ctx2, cancel2 := b.WithDeadline(context.Background(), now.Add(2*time.Second))
+			defer cancel2()
+			s.Add(time.Second)
+			<-ctx.Done()
+			So(ctx2.Err(), ShouldBeNil)
+		})
+		Convey("同一个 Simulator 同时驱动两种风格的时钟", func() {
+			k := NewK8s(s)
+			bt := b.Timer(time.Second)
+			kt := k.NewTimer(2 * time.Second)
+			s.Add(2 * time.Second)
+			So(<-bt.C, ShouldEqual, now.Add(time.Second))
+			So(<-kt.C(), ShouldEqual, now.Add(2*time.Second))
+		})
+	})
+}
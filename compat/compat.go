@@ -0,0 +1,18 @@
+// Package compat 让 clock.Clock（包括 *clock.Simulator）满足其他常用时钟库的接口，
+// 这样，一个 Simulator 就可以同时驱动使用不同时钟库的代码。
+//
+// 这些接口都在本包中按照原样定义，所以不需要导入那些时钟库。
+//
+//   - K8s 满足本包中的 PassiveClock，K8sClock，WithTicker 和 WithDelayedExecution
+//   - Ben 满足 github.com/benbjohnson/clock 的 Clock 的方法形状
+//
+// NOTICE: Go 要求接口方法的返回类型完全相同，
+// K8s 的 NewTimer，NewTicker 和 AfterFunc 返回的是本包的 K8sTimer 和 K8sTicker，
+// 而不是 k8s.io/utils/clock 的 Timer 和 Ticker，
+// 所以只有 PassiveClock 可以直接传给期望 k8s.io/utils/clock 接口的代码，
+// 期望 k8s.io/utils/clock.Clock 等接口的代码，需要改为依赖本包的 K8sClock 等接口。
+//
+// NOTICE: benbjohnson/clock 的 Timer 和 Ticker 是具体的结构体，不是接口，
+// 其他包无法构造它们，所以 Ben 只能提供形状相同的 BenTimer 和 BenTicker。
+// 期望 benbjohnson/clock.Clock 的代码，需要改为依赖本包的 BenClock 这样的接口。
+package compat
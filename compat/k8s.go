@@ -0,0 +1,125 @@
+package compat
+
+import (
+	"time"
+
+	"github.com/jujili/clock"
+)
+
+// PassiveClock 与 k8s.io/utils/clock.PassiveClock 一样
+type PassiveClock interface {
+	Now() time.Time
+	Since(time.Time) time.Duration
+}
+
+// K8sClock 仿照 k8s.io/utils/clock.Clock，
+// 但是 NewTimer 返回的是本包的 K8sTimer，所以两者不能互换
+type K8sClock interface {
+	PassiveClock
+	After(d time.Duration) <-chan time.Time
+	NewTimer(d time.Duration) K8sTimer
+	Sleep(d time.Duration)
+	Tick(d time.Duration) <-chan time.Time
+}
+
+// WithTicker 仿照 k8s.io/utils/clock.WithTicker
+type WithTicker interface {
+	K8sClock
+	NewTicker(time.Duration) K8sTicker
+}
+
+// WithDelayedExecution 仿照 k8s.io/utils/clock.WithDelayedExecution
+type WithDelayedExecution interface {
+	K8sClock
+	AfterFunc(d time.Duration, f func()) K8sTimer
+}
+
+// K8sTimer 与 k8s.io/utils/clock.Timer 一样
+type K8sTimer interface {
+	C() <-chan time.Time
+	Stop() bool
+	Reset(d time.Duration) bool
+}
+
+// K8sTicker 与 k8s.io/utils/clock.Ticker 一样
+type K8sTicker interface {
+	C() <-chan time.Time
+	Stop()
+}
+
+// K8s 用 clock.Clock 实现了本包的 WithTicker 和 WithDelayedExecution。
+// 只有 PassiveClock 与 k8s.io/utils/clock 的同名接口完全相同，详见包的文档。
+type K8s struct {
+	c clock.Clock
+}
+
+// NewK8s 返回基于 c 的 *K8s
+func NewK8s(c clock.Clock) *K8s {
+	return &K8s{c: c}
+}
+
+// Now returns the current time.
+func (k *K8s) Now() time.Time {
+	return k.c.Now()
+}
+
+// Since returns the time elapsed since t.
+func (k *K8s) Since(t time.Time) time.Duration {
+	return k.c.Since(t)
+}
+
+// After waits for the duration to elapse and then sends the current time on
+// the returned channel.
+func (k *K8s) After(d time.Duration) <-chan time.Time {
+	return k.c.After(d)
+}
+
+// NewTimer creates a new Timer that will send the current time on its channel
+// after at least duration d.
+func (k *K8s) NewTimer(d time.Duration) K8sTimer {
+	return k8sTimer{k.c.NewTimer(d)}
+}
+
+// Sleep pauses the current goroutine for at least the duration d.
+func (k *K8s) Sleep(d time.Duration) {
+	k.c.Sleep(d)
+}
+
+// Tick is a convenience wrapper for NewTicker providing access to the ticking
+// channel only.
+func (k *K8s) Tick(d time.Duration) <-chan time.Time {
+	return k.c.Tick(d)
+}
+
+// NewTicker returns a new Ticker containing a channel that will send the
+// current time with a period specified by the duration d.
+func (k *K8s) NewTicker(d time.Duration) K8sTicker {
+	return k8sTicker{k.c.NewTicker(d)}
+}
+
+// AfterFunc waits for the duration to elapse and then calls f in its own goroutine.
+// 与 k8s.io/utils/clock 一样，返回的 Timer 的 C 是 nil。
+func (k *K8s) AfterFunc(d time.Duration, f func()) K8sTimer {
+	return k8sAfterFuncTimer{k8sTimer{k.c.AfterFunc(d, f)}}
+}
+
+type k8sTimer struct {
+	t *clock.Timer
+}
+
+func (t k8sTimer) C() <-chan time.Time        { return t.t.C }
+func (t k8sTimer) Stop() bool                 { return t.t.Stop() }
+func (t k8sTimer) Reset(d time.Duration) bool { return t.t.Reset(d) }
+
+type k8sAfterFuncTimer struct {
+	k8sTimer
+}
+
+func (k8sAfterFuncTimer) C() <-chan time.Time { return nil }
+
+type k8sTicker struct {
+	t *clock.Ticker
+}
+
+func (t k8sTicker) C() <-chan time.Time { return t.t.C }
+func (t k8sTicker) Stop()               { t.t.Stop() }
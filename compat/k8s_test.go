@@ -0,0 +1,70 @@
+package compat
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/jujili/clock"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+// k8sPassiveClock 原样复制自 k8s.io/utils/clock.PassiveClock，
+// 用于确认 *K8s 可以直接传给期望原接口的代码
+type k8sPassiveClock interface {
+	Now() time.Time
+	Since(time.Time) time.Duration
+}
+
+var (
+	_ k8sPassiveClock      = (*K8s)(nil)
+	_ PassiveClock         = (*K8s)(nil)
+	_ K8sClock             = (*K8s)(nil)
+	_ WithTicker           = (*K8s)(nil)
+	_ WithDelayedExecution = (*K8s)(nil)
+)
+
+func Test_K8s(t *testing.T) {
+	Convey("用 Simulator 驱动 k8s 风格的时钟", t, func() {
+		now := time.Date(2020, 5, 20, 0, 0, 0, 0, time.UTC)
+		s := clock.NewSimulator(now)
+		var k WithTicker = NewK8s(s)
+		So(k.Now(), ShouldEqual, now)
+		Convey("Timer", func() {
+			timer := k.NewTimer(time.Second)
+			after := k.After(time.Second)
+			s.Add(time.Second)
+			So(<-timer.C(), ShouldEqual, now.Add(time.Second))
+			So(<-after, ShouldEqual, now.Add(time.Second))
+			So(k.Since(now), ShouldEqual, time.Second)
+			So(timer.Reset(time.Second), ShouldBeFalse)
+			So(timer.Stop(), ShouldBeTrue)
+		})
+		Convey("Ticker", func() {
+			ticker := k.NewTicker(time.Second)
+			tick := k.Tick(time.Second)
+			s.Add(time.Second)
+			So(<-ticker.C(), ShouldEqual, now.Add(time.Second))
+			So(<-tick, ShouldEqual, now.Add(time.Second))
+			ticker.Stop()
+		})
+		Convey("AfterFunc 的 C 是 nil", func() {
+			d := NewK8s(s)
+			done := make(chan struct{})
+			timer := d.AfterFunc(time.Second, func() { close(done) })
+			So(timer.C(), ShouldBeNil)
+			s.Add(time.Second)
+			<-done
+		})
+		Convey("Sleep", func() {
+			done := make(chan struct{})
+			go func() {
+				k.Sleep(time.Second)
+				close(done)
+			}()
+			So(s.BlockUntil(context.Background(), 1), ShouldBeNil)
+			s.Add(time.Second)
+			<-done
+		})
+	})
+}
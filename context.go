@@ -16,12 +16,19 @@ type contextSim struct {
 }
 
 func (s *Simulator) newContextSim(parent context.Context, deadline time.Time) context.Context {
+	return s.newLocalContextSim(parent, deadline, deadline)
+}
+
+// newLocalContextSim 返回的上下文，在 Simulator 的 fireAt 时刻到期，
+// 但是 Deadline 方法返回的是 deadline。
+// 这样的话，deadline 可以是其他时钟上的时间。
+func (s *Simulator) newLocalContextSim(parent context.Context, deadline, fireAt time.Time) context.Context {
 	ctx := &contextSim{
 		Context:  parent,
 		done:     make(chan struct{}),
 		deadline: deadline,
 	}
-	t := s.newTimerFunc(deadline, nil)
+	t := s.newTimerFunc(fireAt, nil)
 	go func() {
 		// 监控上下文的改变
 		select {
@@ -40,6 +47,18 @@ func (s *Simulator) newContextSim(parent context.Context, deadline time.Time) co
 	return ctx
 }
 
+// expiredContextSim 返回已经过期的上下文
+func (s *Simulator) expiredContextSim(parent context.Context, deadline time.Time) context.Context {
+	ctx := &contextSim{
+		Context:  parent,
+		done:     make(chan struct{}),
+		deadline: deadline,
+		err:      context.DeadlineExceeded,
+	}
+	close(ctx.done)
+	return ctx
+}
+
 func (ctx *contextSim) Deadline() (time.Time, bool) {
 	return ctx.deadline, true
 }
@@ -0,0 +1,182 @@
+package clock
+
+import (
+	"sync"
+	"time"
+)
+
+// Edge 决定了 Debouncer 和 Throttler 在时间窗口的哪一端调用函数
+// 可以使用 Leading|Trailing 同时在两端调用
+type Edge int
+
+const (
+	// Trailing 在时间窗口结束的时候调用函数
+	Trailing Edge = 1 << iota
+	// Leading 在时间窗口开始的时候调用函数
+	Leading
+)
+
+// limiter 是 Debouncer 和 Throttler 的共同逻辑
+// 两者唯一的区别是，
+// Debouncer 每次 Call 都会重新开始时间窗口，
+// Throttler 的时间窗口一旦开始，就不会被 Call 延长。
+type limiter struct {
+	mu    sync.Mutex
+	clock Clock
+	d     time.Duration
+	edge  Edge
+	f     func()
+	// restart 为 true 时，每次 Call 都会重置时间窗口
+	restart bool
+	timer   *Timer
+	// armed 表示 timer 已经设置，对应的 fire 还没有运行
+	armed bool
+	// stale 是已经过期，但还没有运行的 fire 的个数，这些 fire 需要被忽略
+	stale int
+	// inWindow 表示正处于时间窗口中
+	inWindow bool
+	// pending 表示有尚未执行的 trailing 调用
+	pending bool
+}
+
+func newLimiter(c Clock, d time.Duration, edge Edge, f func(), restart bool) limiter {
+	if edge&(Leading|Trailing) == 0 {
+		edge = Trailing
+	}
+	return limiter{
+		clock:   c,
+		d:       d,
+		edge:    edge,
+		f:       f,
+		restart: restart,
+	}
+}
+
+// Call 请求调用一次 f
+// 在 Leading 端调用 f 时，f 在 Call 所在的 goroutine 中执行。
+func (l *limiter) Call() {
+	l.mu.Lock()
+	runNow := false
+	switch {
+	case !l.inWindow:
+		l.inWindow = true
+		if l.edge&Leading != 0 {
+			runNow = true
+		} else {
+			l.pending = true
+		}
+		l.startWindow()
+	default:
+		if l.edge&Trailing != 0 {
+			l.pending = true
+		}
+		if l.restart {
+			l.startWindow()
+		}
+	}
+	l.mu.Unlock()
+	if runNow {
+		l.f()
+	}
+}
+
+// Flush 立即执行尚未执行的调用，并结束当前的时间窗口。
+// 没有尚未执行的调用时，什么也不做。
+func (l *limiter) Flush() {
+	l.mu.Lock()
+	pending := l.pending
+	l.stop()
+	l.mu.Unlock()
+	if pending {
+		l.f()
+	}
+}
+
+// Cancel 放弃尚未执行的调用，并结束当前的时间窗口
+func (l *limiter) Cancel() {
+	l.mu.Lock()
+	l.stop()
+	l.mu.Unlock()
+}
+
+// NOTICE: 务必在 l 的临界区内运行此方法
+func (l *limiter) startWindow() {
+	defer func() { l.armed = true }()
+	if l.timer == nil {
+		l.timer = l.clock.AfterFunc(l.d, l.fire)
+		return
+	}
+	// Reset 返回 false，说明 timer 已经触发，
+	// armed 的话，那一次的 fire 还没有运行，之后运行时需要忽略
+	if !l.timer.Reset(l.d) && l.armed {
+		l.stale++
+	}
+}
+
+// NOTICE: 务必在 l 的临界区内运行此方法
+func (l *limiter) stop() {
+	l.pending = false
+	l.inWindow = false
+	if l.timer != nil && !l.timer.Stop() && l.armed {
+		l.stale++
+	}
+	l.armed = false
+}
+
+func (l *limiter) fire() {
+	l.mu.Lock()
+	// 已经触发的 timer 被 Reset 或 Stop 后，
+	// 上一次触发的 fire 有可能在这之后才运行，需要忽略掉这一次的 fire。
+	// NOTICE: 不能比较墙上时间，墙上时间被往回调整的话，时间窗口会永远不结束
+	if l.stale > 0 {
+		l.stale--
+		l.mu.Unlock()
+		return
+	}
+	l.armed = false
+	if !l.inWindow {
+		l.mu.Unlock()
+		return
+	}
+	if !l.pending {
+		l.inWindow = false
+		l.mu.Unlock()
+		return
+	}
+	l.pending = false
+	if l.restart {
+		l.inWindow = false
+	} else {
+		// Throttler 在 trailing 端调用 f 后，
+		// 需要开始新的时间窗口，才能保证 f 的调用间隔不小于 d
+		l.startWindow()
+	}
+	l.mu.Unlock()
+	l.f()
+}
+
+// Debouncer 会把时间间隔小于 d 的一连串 Call 合并成一次 f 的调用
+type Debouncer struct {
+	limiter
+}
+
+// Debounce 返回一个基于 c 的时间线的 *Debouncer
+// edge 为 0 时，按照 Trailing 处理
+func Debounce(c Clock, d time.Duration, edge Edge, f func()) *Debouncer {
+	return &Debouncer{
+		limiter: newLimiter(c, d, edge, f, true),
+	}
+}
+
+// Throttler 保证 f 在每个长度为 d 的时间窗口内，最多被调用一次
+type Throttler struct {
+	limiter
+}
+
+// Throttle 返回一个基于 c 的时间线的 *Throttler
+// edge 为 0 时，按照 Trailing 处理
+func Throttle(c Clock, d time.Duration, edge Edge, f func()) *Throttler {
+	return &Throttler{
+		limiter: newLimiter(c, d, edge, f, false),
+	}
+}
@@ -0,0 +1,167 @@
+package clock
+
+import (
+	"testing"
+	"time"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+// received 返回 c 在短时间内收到的次数
+// NOTICE: AfterFunc 的 f 是并发执行的，time.Sleep 是为了等待 f 执行完毕
+// 但也不是 100% 的保证
+func received(c chan time.Time) int {
+	time.Sleep(10 * time.Millisecond)
+	return len(c)
+}
+
+func Test_Debounce(t *testing.T) {
+	Convey("新建一个 Simulator s", t, func() {
+		now := time.Now()
+		d := time.Second
+		s := NewSimulator(now)
+		calls := make(chan time.Time, 10)
+		f := func() { calls <- s.Now() }
+		Convey("Trailing 的 Debouncer", func() {
+			db := Debounce(s, d, Trailing, f)
+			Convey("连续的 Call 只会在最后一次 Call 的 d 后调用一次 f", func() {
+				db.Call()
+				s.Add(d / 2)
+				db.Call()
+				s.Add(d / 2)
+				So(received(calls), ShouldEqual, 0)
+				s.Add(d / 2)
+				So(received(calls), ShouldEqual, 1)
+				So(<-calls, ShouldEqual, now.Add(3*d/2))
+			})
+			Convey("Flush 会立即调用 f", func() {
+				db.Call()
+				db.Flush()
+				So(len(calls), ShouldEqual, 1)
+				s.Add(2 * d)
+				So(received(calls), ShouldEqual, 1)
+			})
+			Convey("没有待执行的调用时，Flush 不会调用 f", func() {
+				db.Flush()
+				So(len(calls), ShouldEqual, 0)
+			})
+			Convey("Cancel 后，不会调用 f", func() {
+				db.Call()
+				db.Cancel()
+				s.Add(2 * d)
+				So(received(calls), ShouldEqual, 0)
+			})
+			Convey("timer 被 Reset 之前已经触发的 fire 会被忽略", func() {
+				db.Call()
+				// 持有锁，让触发的 fire 等到 Reset 之后才运行
+				db.mu.Lock()
+				s.Add(d)
+				db.pending = true
+				db.startWindow()
+				So(db.stale, ShouldEqual, 1)
+				db.mu.Unlock()
+				So(received(calls), ShouldEqual, 0)
+				s.Add(d)
+				So(received(calls), ShouldEqual, 1)
+			})
+			Convey("时间窗口内，墙上时间被往回调整，依然会在 d 后调用 f", func() {
+				db.Call()
+				s.StepWall(-time.Hour)
+				s.Add(2 * d)
+				So(received(calls), ShouldEqual, 1)
+			})
+		})
+		Convey("Leading 的 Debouncer", func() {
+			db := Debounce(s, d, Leading, f)
+			Convey("只有第一次 Call 会调用 f", func() {
+				db.Call()
+				So(len(calls), ShouldEqual, 1)
+				db.Call()
+				s.Add(d / 2)
+				db.Call()
+				s.Add(2 * d)
+				So(received(calls), ShouldEqual, 1)
+				Convey("时间窗口结束后，Call 会再次调用 f", func() {
+					db.Call()
+					So(len(calls), ShouldEqual, 2)
+				})
+			})
+		})
+		Convey("Leading|Trailing 的 Debouncer", func() {
+			db := Debounce(s, d, Leading|Trailing, f)
+			Convey("两端都会调用 f", func() {
+				db.Call()
+				db.Call()
+				So(len(calls), ShouldEqual, 1)
+				s.Add(d)
+				So(received(calls), ShouldEqual, 2)
+			})
+			Convey("只 Call 一次的话，只在开始的时候调用 f", func() {
+				db.Call()
+				s.Add(d)
+				So(received(calls), ShouldEqual, 1)
+			})
+		})
+	})
+}
+
+func Test_Throttle(t *testing.T) {
+	Convey("新建一个 Simulator s", t, func() {
+		now := time.Now()
+		d := time.Second
+		s := NewSimulator(now)
+		calls := make(chan time.Time, 10)
+		f := func() { calls <- s.Now() }
+		Convey("Leading 的 Throttler", func() {
+			th := Throttle(s, d, Leading, f)
+			Convey("时间窗口内，只会调用一次 f", func() {
+				th.Call()
+				s.Add(d / 2)
+				th.Call()
+				So(received(calls), ShouldEqual, 1)
+				Convey("Call 不会延长时间窗口", func() {
+					s.Add(d / 2)
+					th.Call()
+					So(received(calls), ShouldEqual, 2)
+				})
+			})
+		})
+		Convey("Trailing 的 Throttler", func() {
+			th := Throttle(s, d, Trailing, f)
+			Convey("在时间窗口结束时调用 f", func() {
+				th.Call()
+				s.Add(d / 2)
+				th.Call()
+				So(received(calls), ShouldEqual, 0)
+				s.Add(d / 2)
+				So(received(calls), ShouldEqual, 1)
+				So(<-calls, ShouldEqual, now.Add(d))
+			})
+			Convey("持续 Call 时，f 的调用间隔是 d", func() {
+				for i := 0; i < 4; i++ {
+					th.Call()
+					s.Add(d / 2)
+					received(calls)
+				}
+				So(len(calls), ShouldEqual, 2)
+				So(<-calls, ShouldEqual, now.Add(d))
+				So(<-calls, ShouldEqual, now.Add(2*d))
+			})
+		})
+	})
+}
+
+func Test_Debounce_realClock(t *testing.T) {
+	Convey("基于 realClock 的 Debouncer", t, func() {
+		calls := make(chan time.Time, 10)
+		db := Debounce(NewRealClock(), 20*time.Millisecond, 0, func() {
+			calls <- time.Now()
+		})
+		Convey("也只会调用一次 f", func() {
+			db.Call()
+			db.Call()
+			time.Sleep(60 * time.Millisecond)
+			So(len(calls), ShouldEqual, 1)
+		})
+	})
+}
@@ -0,0 +1,105 @@
+package clock
+
+import (
+	"fmt"
+	"math"
+	"os"
+	"strconv"
+	"time"
+)
+
+// 环境变量的名称
+const (
+	// EnvMode 选择时钟的种类：real，sim，offset 或 scaled，默认为 real
+	EnvMode = "CLOCK_MODE"
+	// EnvStart 是 sim 和 scaled 时钟的起始时间，RFC 3339 格式，默认为当前时间。
+	// offset 时钟没有设置 EnvOffset 的话，会让当前时间变成 EnvStart。
+	EnvStart = "CLOCK_START"
+	// EnvOffset 是 offset 时钟的偏移，time.ParseDuration 格式
+	EnvOffset = "CLOCK_OFFSET"
+	// EnvScale 是 scaled 时钟的速度倍数，必须大于 0
+	EnvScale = "CLOCK_SCALE"
+)
+
+// FromEnv 根据环境变量选择时钟，方便在 main 中一行代码注入时钟
+//
+//	ctx := clock.Set(context.Background(), clock.MustFromEnv())
+//
+// CLOCK_MODE 的取值
+//   - real 或为空：NewRealClock()
+//   - sim：从 CLOCK_START 开始的 *Simulator，需要远程驱动的话，请使用 clockhttp.FromEnv
+//   - offset：NewOffsetClock(CLOCK_OFFSET)
+//   - scaled：NewScaledClock(CLOCK_START, CLOCK_SCALE)
+func FromEnv() (Clock, error) {
+	return FromLookup(os.LookupEnv)
+}
+
+// MustFromEnv 与 FromEnv 一样，只是出错时会 panic
+func MustFromEnv() Clock {
+	c, err := FromEnv()
+	if err != nil {
+		panic(err)
+	}
+	return c
+}
+
+// FromLookup 与 FromEnv 一样，只是使用 lookup 读取变量，
+// 方便从命令行参数或配置文件中读取。
+func FromLookup(lookup func(key string) (string, bool)) (Clock, error) {
+	get := func(key string) string {
+		v, _ := lookup(key)
+		return v
+	}
+	start := func() (time.Time, error) {
+		v := get(EnvStart)
+		if v == "" {
+			return time.Now(), nil
+		}
+		t, err := time.Parse(time.RFC3339Nano, v)
+		if err != nil {
+			return time.Time{}, fmt.Errorf("clock: 无效的 %s: %w", EnvStart, err)
+		}
+		return t, nil
+	}
+	switch mode := get(EnvMode); mode {
+	case "", "real":
+		return NewRealClock(), nil
+	case "sim":
+		t, err := start()
+		if err != nil {
+			return nil, err
+		}
+		return NewSimulator(t), nil
+	case "offset":
+		if v := get(EnvOffset); v != "" {
+			d, err := time.ParseDuration(v)
+			if err != nil {
+				return nil, fmt.Errorf("clock: 无效的 %s: %w", EnvOffset, err)
+			}
+			return NewOffsetClock(d), nil
+		}
+		if get(EnvStart) == "" {
+			return nil, fmt.Errorf("clock: %s=offset 需要 %s 或 %s", EnvMode, EnvOffset, EnvStart)
+		}
+		t, err := start()
+		if err != nil {
+			return nil, err
+		}
+		return NewOffsetClock(time.Until(t)), nil
+	case "scaled":
+		scale, err := strconv.ParseFloat(get(EnvScale), 64)
+		if err != nil {
+			return nil, fmt.Errorf("clock: 无效的 %s: %w", EnvScale, err)
+		}
+		if !(scale > 0) || math.IsInf(scale, 1) {
+			return nil, fmt.Errorf("clock: %s 必须大于 0，实际是 %v", EnvScale, scale)
+		}
+		t, err := start()
+		if err != nil {
+			return nil, err
+		}
+		return NewScaledClock(t, scale), nil
+	default:
+		return nil, fmt.Errorf("clock: 未知的 %s %q", EnvMode, mode)
+	}
+}
@@ -0,0 +1,90 @@
+package clock
+
+import (
+	"testing"
+	"time"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func lookupMap(m map[string]string) func(string) (string, bool) {
+	return func(key string) (string, bool) {
+		v, ok := m[key]
+		return v, ok
+	}
+}
+
+func Test_FromLookup(t *testing.T) {
+	Convey("根据变量选择时钟", t, func() {
+		start := time.Date(2020, 5, 20, 0, 0, 0, 0, time.UTC)
+		Convey("没有设置时，使用真实时钟", func() {
+			c, err := FromLookup(lookupMap(nil))
+			So(err, ShouldBeNil)
+			So(c, ShouldResemble, NewRealClock())
+		})
+		Convey("real", func() {
+			c, err := FromLookup(lookupMap(map[string]string{EnvMode: "real"}))
+			So(err, ShouldBeNil)
+			So(c, ShouldResemble, NewRealClock())
+		})
+		Convey("sim", func() {
+			c, err := FromLookup(lookupMap(map[string]string{
+				EnvMode:  "sim",
+				EnvStart: "2020-05-20T00:00:00Z",
+			}))
+			So(err, ShouldBeNil)
+			s, ok := c.(*Simulator)
+			So(ok, ShouldBeTrue)
+			So(s.Now(), ShouldEqual, start)
+		})
+		Convey("sim 的起始时间格式错误", func() {
+			_, err := FromLookup(lookupMap(map[string]string{
+				EnvMode:  "sim",
+				EnvStart: "yesterday",
+			}))
+			So(err, ShouldNotBeNil)
+		})
+		Convey("offset", func() {
+			c, err := FromLookup(lookupMap(map[string]string{
+				EnvMode:   "offset",
+				EnvOffset: "-1h",
+			}))
+			So(err, ShouldBeNil)
+			So(c.Now(), ShouldHappenWithin, 10*time.Millisecond, time.Now().Add(-time.Hour))
+		})
+		Convey("offset 可以使用起始时间", func() {
+			c, err := FromLookup(lookupMap(map[string]string{
+				EnvMode:  "offset",
+				EnvStart: "2020-05-20T00:00:00Z",
+			}))
+			So(err, ShouldBeNil)
+			So(c.Now(), ShouldHappenWithin, 10*time.Millisecond, start)
+		})
+		Convey("offset 缺少参数", func() {
+			_, err := FromLookup(lookupMap(map[string]string{EnvMode: "offset"}))
+			So(err, ShouldNotBeNil)
+		})
+		Convey("scaled", func() {
+			c, err := FromLookup(lookupMap(map[string]string{
+				EnvMode:  "scaled",
+				EnvStart: "2020-05-20T00:00:00Z",
+				EnvScale: "60",
+			}))
+			So(err, ShouldBeNil)
+			So(c.Now(), ShouldHappenWithin, time.Second, start)
+		})
+		Convey("scaled 的倍数必须大于 0", func() {
+			for _, scale := range []string{"", "0", "-1", "NaN", "Inf"} {
+				_, err := FromLookup(lookupMap(map[string]string{
+					EnvMode:  "scaled",
+					EnvScale: scale,
+				}))
+				So(err, ShouldNotBeNil)
+			}
+		})
+		Convey("未知的种类", func() {
+			_, err := FromLookup(lookupMap(map[string]string{EnvMode: "fast"}))
+			So(err, ShouldNotBeNil)
+		})
+	})
+}
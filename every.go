@@ -1,34 +1,180 @@
 package clock
 
 import (
+	"context"
+	"sync"
 	"time"
 )
 
-// nextDayTime 是为了 EveryDay 函数准备的
-// 是为了每天在 hour:minute:second 提供 tick
-// NOTICE: hour 是 24 小时制的小时
-func nextDayTime(now time.Time, hour, minute, second int) time.Time {
-	yyyy, mm, dd := now.Year(), now.Month(), now.Day()
-	loc := time.Now().Location()
-	next := time.Date(yyyy, mm, dd, hour, minute, second, 0, loc)
-	if now.Before(next) {
-		return next
-	}
-	return next.Add(24 * time.Hour)
-}
-
 // EveryDay returns a channel which
 // output a time.Time by your setting
+// 使用 s 的墙上时间所在的时区，跨过夏令时切换时，钟面时间不变。
+// 无法停止，需要停止的话，请使用 s.Every(ctx, Daily(...))。
+// 没有接收的时间最多保留 maxForwardQueue 个，详见 Every
+// NOTICE: hour 是 24 小时制的小时
 func (s *Simulator) EveryDay(hour, minute, second int) <-chan time.Time {
-	s.Lock()
-	defer s.Unlock()
+	return s.Every(context.Background(), Daily(s.Now().Location(), hour, minute, second))
+}
+
+// Every 返回的 channel 在 r 的每一个时刻，发送当时的时间，直到 ctx 结束。
+// s 不会因为没有接收而阻塞，没有接收的时间会按顺序保留，之后依然可以接收，
+// 但是最多保留 maxForwardQueue 个，更多的时刻与 Ticker 一样会被丢弃。
+// 时刻来自 s，ctx 只用于停止：ctx 结束以后，任务会从 s 中移除，还没有发送的时间会被丢弃。
+// NOTICE: 不再接收的话，请结束 ctx，否则发送的 goroutine 和任务会一直存在
+func (s *Simulator) Every(ctx context.Context, r Recurring) <-chan time.Time {
+	s.mu.Lock()
+	defer s.mu.Unlock()
 	c := make(chan time.Time, 1)
+	done := ctx.Done()
+	send := forward(c, done)
+	next := r.Next(s.wallNow())
 	run := func(t *task) *task {
-		c <- s.now // 必须要送达
-		t.deadline = t.deadline.Add(24 * time.Hour)
+		// 每一个时刻都必须送达，但是不能在临界区内阻塞，
+		// 所以交给 send 在临界区外按顺序发送
+		send(s.wallNow())
+		// 从这一次的时刻往后找，墙上时间被调整过的话，也不会重复或者遗漏
+		next = r.Next(next)
+		t.deadline = s.monoOf(next)
 		return t
 	}
-	task := newTask(nextDayTime(s.now, hour, minute, second), run)
+	task := newTask(s.monoOf(next), run)
+	task.periodic = true
 	s.accept(task)
+	if done != nil {
+		go func() {
+			<-done
+			s.mu.Lock()
+			defer s.mu.Unlock()
+			s.stopTask(task)
+		}()
+	}
 	return c
 }
+
+// EveryWeek 返回的 channel 在每周 weekday 的 hour:minute:second，发送当时的时间，直到 ctx 结束。
+// 使用 s 的墙上时间所在的时区
+func (s *Simulator) EveryWeek(ctx context.Context, weekday time.Weekday, hour, minute, second int) <-chan time.Time {
+	return s.Every(ctx, Weekly(s.Now().Location(), weekday, hour, minute, second))
+}
+
+// EveryMonth 返回的 channel 在每月第 day 天的 hour:minute:second，发送当时的时间，直到 ctx 结束。
+// day 为负数时，从月末开始数，详见 Monthly。
+// 使用 s 的墙上时间所在的时区
+func (s *Simulator) EveryMonth(ctx context.Context, day, hour, minute, second int) <-chan time.Time {
+	return s.Every(ctx, Monthly(s.Now().Location(), day, hour, minute, second))
+}
+
+// EveryNthWeekday 返回的 channel 在每月第 n 个 weekday 的 hour:minute:second，发送当时的时间，直到 ctx 结束。
+// n 为负数时，从月末开始数，详见 NthWeekday。
+// 使用 s 的墙上时间所在的时区
+func (s *Simulator) EveryNthWeekday(ctx context.Context, n int, weekday time.Weekday, hour, minute, second int) <-chan time.Time {
+	return s.Every(ctx, NthWeekday(s.Now().Location(), n, weekday, hour, minute, second))
+}
+
+// EveryInterval 返回的 channel 在与 start 对齐的每一个 period，发送当时的时间，直到 ctx 结束，详见 Interval。
+func (s *Simulator) EveryInterval(ctx context.Context, start time.Time, period time.Duration) <-chan time.Time {
+	return s.Every(ctx, Interval(start, period))
+}
+
+// Every 返回的 channel 在 r 的每一个时刻，发送当时的时间，直到 ctx 结束。
+// 时钟来自 Get(ctx)，所以真实的和模拟的时钟都可以使用。
+// 每一个时刻都会送达，不会被丢弃：没有及时接收的话，下一个时刻会在接收以后才发送。
+func Every(ctx context.Context, r Recurring) <-chan time.Time {
+	c := Get(ctx)
+	out := make(chan time.Time, 1)
+	go func() {
+		next := r.Next(c.Now())
+		for {
+			timer := c.NewTimer(c.Until(next))
+			var now time.Time
+			select {
+			case now = <-timer.C:
+			case <-ctx.Done():
+				timer.Stop()
+				return
+			}
+			select {
+			case out <- now:
+			case <-ctx.Done():
+				return
+			}
+			// 从这一次的时刻往后找，免得接收得晚了，遗漏时刻
+			next = r.Next(next)
+		}
+	}()
+	return out
+}
+
+// EveryDay 是 Every(ctx, Daily(loc, hour, minute, second)) 的简写
+func EveryDay(ctx context.Context, loc *time.Location, hour, minute, second int) <-chan time.Time {
+	return Every(ctx, Daily(loc, hour, minute, second))
+}
+
+// EveryWeek 是 Every(ctx, Weekly(loc, weekday, hour, minute, second)) 的简写
+func EveryWeek(ctx context.Context, loc *time.Location, weekday time.Weekday, hour, minute, second int) <-chan time.Time {
+	return Every(ctx, Weekly(loc, weekday, hour, minute, second))
+}
+
+// EveryMonth 是 Every(ctx, Monthly(loc, day, hour, minute, second)) 的简写
+func EveryMonth(ctx context.Context, loc *time.Location, day, hour, minute, second int) <-chan time.Time {
+	return Every(ctx, Monthly(loc, day, hour, minute, second))
+}
+
+// EveryNthWeekday 是 Every(ctx, NthWeekday(loc, n, weekday, hour, minute, second)) 的简写
+func EveryNthWeekday(ctx context.Context, loc *time.Location, n int, weekday time.Weekday, hour, minute, second int) <-chan time.Time {
+	return Every(ctx, NthWeekday(loc, n, weekday, hour, minute, second))
+}
+
+// EveryInterval 是 Every(ctx, Interval(start, period)) 的简写
+func EveryInterval(ctx context.Context, start time.Time, period time.Duration) <-chan time.Time {
+	return Every(ctx, Interval(start, period))
+}
+
+// maxForwardQueue 是 forward 的队列中，最多保留的时间的个数
+const maxForwardQueue = 256
+
+// forward 返回的 send 会把时间放入队列，由另一个 goroutine 按顺序发送到 out。
+// send 不会阻塞，所以可以在临界区内调用。
+// 队列中已经有 maxForwardQueue 个时间的话，新的时间会被丢弃，
+// 所以没有人接收 out 的话，占用的内存也是有限的。
+// 只有队列中有时间的时候，才会有发送的 goroutine，队列空了以后就会退出，
+// 所以不再使用的 out 不会让 goroutine 一直存在。
+// done 关闭以后，发送的 goroutine 会丢弃队列并退出，之后 send 不再做任何事，
+// done 为 nil 的话，就一直等到 out 被接收。
+func forward(out chan<- time.Time, done <-chan struct{}) (send func(time.Time)) {
+	var mu sync.Mutex
+	var queue []time.Time
+	var sending, stopped bool
+	run := func() {
+		for {
+			mu.Lock()
+			if len(queue) == 0 || stopped {
+				queue, sending = nil, false
+				mu.Unlock()
+				return
+			}
+			v := queue[0]
+			queue = queue[1:]
+			mu.Unlock()
+			select {
+			case out <- v:
+			case <-done:
+				mu.Lock()
+				stopped = true
+				mu.Unlock()
+			}
+		}
+	}
+	return func(v time.Time) {
+		mu.Lock()
+		defer mu.Unlock()
+		if stopped || len(queue) >= maxForwardQueue {
+			return
+		}
+		queue = append(queue, v)
+		if !sending {
+			sending = true
+			go run()
+		}
+	}
+}
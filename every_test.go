@@ -1,51 +1,14 @@
 package clock
 
 import (
+	"context"
+	"runtime"
 	"testing"
 	"time"
 
 	. "github.com/smartystreets/goconvey/convey"
 )
 
-func Test_nextDayTime(t *testing.T) {
-	Convey("测试 nextDayTime 函数", t, func() {
-		yyyy, mm, dd := 2020, time.Month(5), 18
-		h, m, s, ns := 21, 40, 34, 0
-		Convey("设置时间晚于 now 的时间段", func() {
-			loc := time.Now().Location()
-			now := time.Date(yyyy, mm, dd, h, m, s, ns, loc)
-			So(m, ShouldBeLessThan, 60)
-			Convey("则下一个时间点在当天", func() {
-				expected := now.Add(time.Minute)
-				m++
-				actual := nextDayTime(now, h, m, s)
-				So(actual, ShouldEqual, expected)
-			})
-		})
-		Convey("设置时间早于 now 的时间段", func() {
-			loc := time.Now().Location()
-			now := time.Date(yyyy, mm, dd, h, m, s, ns, loc)
-			So(m, ShouldBeGreaterThanOrEqualTo, 0)
-			Convey("则下一个时间点在下一天", func() {
-				m--
-				actual := nextDayTime(now, h, m, s)
-				expected := now.Add(-time.Minute).Add(24 * time.Hour)
-				So(actual, ShouldEqual, expected)
-			})
-		})
-		Convey("设置时间等于 now 的时间段", func() {
-			loc := time.Now().Location()
-			ns = 0
-			now := time.Date(yyyy, mm, dd, h, m, s, ns, loc)
-			Convey("则下一个时间点在下一天", func() {
-				actual := nextDayTime(now, h, m, s)
-				expected := now.Add(24 * time.Hour)
-				So(actual, ShouldEqual, expected)
-			})
-		})
-	})
-}
-
 func Test_Simulator_EveryDay(t *testing.T) {
 	Convey("当前时间是 2020-05-20 15:20:13.14", t, func() {
 		yyyy, mm, dd := 2020, time.Month(5), 20
@@ -66,5 +29,215 @@ func Test_Simulator_EveryDay(t *testing.T) {
 				So(actual, ShouldEqual, expected)
 			}
 		})
+		Convey("没有接收时，Simulator 也不会被阻塞，之后依然能按顺序接收", func() {
+			everyDayChan := clock.EveryDay(0, 0, 0)
+			clock.Set(now.Add(3 * 24 * time.Hour))
+			So(clock.Now(), ShouldEqual, now.Add(3*24*time.Hour))
+			for i := 0; i < 3; i++ {
+				expected = expected.Add(24 * time.Hour)
+				So(<-everyDayChan, ShouldEqual, expected)
+			}
+		})
+	})
+	Convey("EveryDay 使用 s 的时区，跨过夏令时切换时，钟面时间不变", t, func() {
+		loc, err := time.LoadLocation("America/New_York")
+		So(err, ShouldBeNil)
+		s := NewSimulator(time.Date(2024, 3, 9, 12, 0, 0, 0, loc))
+		c := s.EveryDay(9, 0, 0)
+		s.Set(time.Date(2024, 3, 11, 12, 0, 0, 0, loc))
+		So(<-c, ShouldEqual, time.Date(2024, 3, 10, 9, 0, 0, 0, loc))
+		So(<-c, ShouldEqual, time.Date(2024, 3, 11, 9, 0, 0, 0, loc))
+	})
+}
+
+func Test_Simulator_Every(t *testing.T) {
+	Convey("当前时间是 2024-01-01 12:00 UTC，周一", t, func() {
+		now := utc(2024, 1, 1, 12, 0)
+		s := NewSimulator(now)
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		Convey("EveryWeek 在每周三的 09:00 发送", func() {
+			c := s.EveryWeek(ctx, time.Wednesday, 9, 0, 0)
+			So(s.Pending(), ShouldResemble, []time.Time{utc(2024, 1, 3, 9, 0)})
+			s.Set(utc(2024, 1, 18, 0, 0))
+			So(<-c, ShouldEqual, utc(2024, 1, 3, 9, 0))
+			So(<-c, ShouldEqual, utc(2024, 1, 10, 9, 0))
+			So(<-c, ShouldEqual, utc(2024, 1, 17, 9, 0))
+			So(s.Pending(), ShouldResemble, []time.Time{utc(2024, 1, 24, 9, 0)})
+		})
+		Convey("EveryMonth 在每月最后一天发送", func() {
+			c := s.EveryMonth(ctx, -1, 0, 0, 0)
+			s.Set(utc(2024, 4, 1, 0, 0))
+			So(<-c, ShouldEqual, utc(2024, 1, 31, 0, 0))
+			So(<-c, ShouldEqual, utc(2024, 2, 29, 0, 0))
+			So(<-c, ShouldEqual, utc(2024, 3, 31, 0, 0))
+		})
+		Convey("EveryNthWeekday 在每月第一个周一发送", func() {
+			c := s.EveryNthWeekday(ctx, 1, time.Monday, 12, 0, 0)
+			s.Set(utc(2024, 3, 5, 0, 0))
+			So(<-c, ShouldEqual, utc(2024, 2, 5, 12, 0))
+			So(<-c, ShouldEqual, utc(2024, 3, 4, 12, 0))
+		})
+		Convey("EveryInterval 与 start 对齐，而不是与当前时间对齐", func() {
+			c := s.EveryInterval(ctx, utc(2024, 1, 1, 0, 0), 5*time.Hour)
+			So(s.Pending(), ShouldResemble, []time.Time{utc(2024, 1, 1, 15, 0)})
+			s.Add(8 * time.Hour)
+			So(<-c, ShouldEqual, utc(2024, 1, 1, 15, 0))
+			So(<-c, ShouldEqual, utc(2024, 1, 1, 20, 0))
+		})
+		Convey("RunUntilIdle 不会等待这些周期性的任务", func() {
+			s.EveryMonth(ctx, 1, 0, 0, 0)
+			stats, err := s.RunUntilIdle(0)
+			So(err, ShouldBeNil)
+			So(stats.Events, ShouldEqual, 0)
+		})
+		Convey("ctx 结束以后，任务从 s 中移除，不再发送", func() {
+			before := runtime.NumGoroutine()
+			c := s.EveryInterval(ctx, now, time.Hour)
+			s.Add(2 * time.Hour)
+			cancel()
+			So(waitPending(s, 0), ShouldBeTrue)
+			s.Add(2 * time.Hour)
+			So(waitGoroutines(before), ShouldBeTrue)
+			for i := 0; i < 2; i++ {
+				select {
+				case v := <-c:
+					So(v, ShouldHappenBefore, utc(2024, 1, 1, 14, 1))
+				default:
+				}
+			}
+			select {
+			case <-c:
+				So("不应该再发送", ShouldBeEmpty)
+			default:
+			}
+		})
+	})
+}
+
+// waitPending 等待 s 中的任务数量降到 n，超时的话，返回 false
+func waitPending(s *Simulator, n int) bool {
+	for i := 0; i < 100; i++ {
+		if len(s.Pending()) == n {
+			return true
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	return false
+}
+
+func Test_Every(t *testing.T) {
+	Convey("从 ctx 中的时钟获取时刻", t, func() {
+		now := utc(2024, 1, 1, 12, 0)
+		s := NewSimulator(now)
+		ctx, cancel := context.WithCancel(Set(context.Background(), s))
+		defer cancel()
+		Convey("EveryWeek 在每周三的 09:00 发送", func() {
+			c := EveryWeek(ctx, time.UTC, time.Wednesday, 9, 0, 0)
+			So(s.BlockUntil(ctx, 1), ShouldBeNil)
+			So(s.Pending(), ShouldResemble, []time.Time{utc(2024, 1, 3, 9, 0)})
+			s.Set(utc(2024, 1, 3, 9, 0))
+			So(<-c, ShouldEqual, utc(2024, 1, 3, 9, 0))
+			So(s.BlockUntil(ctx, 1), ShouldBeNil)
+			So(s.Pending(), ShouldResemble, []time.Time{utc(2024, 1, 10, 9, 0)})
+		})
+		Convey("没有及时接收的话，每一个时刻依然都会发送", func() {
+			c := EveryDay(ctx, time.UTC, 0, 0, 0)
+			So(s.BlockUntil(ctx, 1), ShouldBeNil)
+			s.Set(utc(2024, 1, 2, 0, 0))
+			So(s.BlockUntil(ctx, 1), ShouldBeNil)
+			s.Set(utc(2024, 1, 3, 12, 0))
+			So(<-c, ShouldEqual, utc(2024, 1, 2, 0, 0))
+			So(<-c, ShouldEqual, utc(2024, 1, 3, 0, 0))
+		})
+		Convey("ctx 结束以后，不再等待", func() {
+			EveryMonth(ctx, time.UTC, 1, 0, 0, 0)
+			EveryNthWeekday(ctx, time.UTC, 1, time.Monday, 0, 0, 0)
+			So(s.BlockUntil(ctx, 2), ShouldBeNil)
+			cancel()
+			for i := 0; i < 100 && len(s.Pending()) > 0; i++ {
+				time.Sleep(time.Millisecond)
+			}
+			So(s.Pending(), ShouldBeEmpty)
+		})
+		Convey("也可以使用真实的时钟", func() {
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+			start := time.Now()
+			c := EveryInterval(ctx, start, 20*time.Millisecond)
+			first := <-c
+			second := <-c
+			So(time.Since(start), ShouldBeGreaterThanOrEqualTo, 40*time.Millisecond)
+			So(second.Sub(first), ShouldBeGreaterThan, 0)
+		})
+	})
+}
+
+func Test_forward(t *testing.T) {
+	Convey("forward 按顺序发送，并且不会留下 goroutine", t, func() {
+		before := runtime.NumGoroutine()
+		out := make(chan time.Time, 1)
+		done := make(chan struct{})
+		send := forward(out, done)
+		t0 := time.Date(2020, 5, 20, 0, 0, 0, 0, time.UTC)
+		Convey("队列中的时间按顺序送达，送完以后 goroutine 退出", func() {
+			for i := 0; i < 3; i++ {
+				send(t0.Add(time.Duration(i) * time.Hour))
+			}
+			for i := 0; i < 3; i++ {
+				So(<-out, ShouldEqual, t0.Add(time.Duration(i)*time.Hour))
+			}
+			So(waitGoroutines(before), ShouldBeTrue)
+		})
+		Convey("没有人接收的话，关闭 done 以后 goroutine 退出", func() {
+			for i := 0; i < 3; i++ {
+				send(t0)
+			}
+			close(done)
+			So(waitGoroutines(before), ShouldBeTrue)
+			send(t0)
+			So(waitGoroutines(before), ShouldBeTrue)
+		})
+	})
+	Convey("没有人接收的话，EveryDay 占用的内存和 goroutine 都是有限的", t, func() {
+		before := runtime.NumGoroutine()
+		s := NewSimulator(time.Date(2020, 5, 20, 0, 0, 0, 0, time.UTC))
+		c := s.EveryDay(0, 0, 0)
+		s.Add(365 * 24 * time.Hour)
+		// 最多只有一个发送的 goroutine
+		So(runtime.NumGoroutine(), ShouldBeLessThanOrEqualTo, before+1)
+		received := 0
+		for {
+			select {
+			case <-c:
+				received++
+				continue
+			case <-time.After(100 * time.Millisecond):
+			}
+			break
+		}
+		// c 的缓存，正在发送的时间和队列
+		So(received, ShouldBeLessThanOrEqualTo, maxForwardQueue+2)
+		So(received, ShouldBeGreaterThan, 0)
+		So(waitGoroutines(before), ShouldBeTrue)
 	})
+	Convey("丢弃的 Simulator 不会留下 goroutine", t, func() {
+		before := runtime.NumGoroutine()
+		for i := 0; i < 100; i++ {
+			s := NewSimulator(time.Date(2020, 5, 20, 0, 0, 0, 0, time.UTC))
+			_ = s.EveryDay(0, 0, 0)
+		}
+		So(waitGoroutines(before), ShouldBeTrue)
+	})
+}
+
+// waitGoroutines 等待 goroutine 的数量降到 n 以下，超时的话，返回 false
+func waitGoroutines(n int) bool {
+	for i := 0; i < 100; i++ {
+		if runtime.NumGoroutine() <= n {
+			return true
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	return false
 }
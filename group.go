@@ -0,0 +1,294 @@
+package clock
+
+import (
+	"context"
+	"math"
+	"sort"
+	"sync"
+	"time"
+)
+
+// ClockGroup 从一个主 Simulator 派生出多个节点的时钟，
+// 每个节点都有自己的偏移，漂移率和跳变。
+//
+// 所有节点的 timer 都放在主 Simulator 中，
+// 所以，不论节点的时钟相差多少，所有的 timer 都按照全局一致的虚拟顺序触发。
+// 用于重现只在时钟偏移下才会出现的租约或选主问题。
+type ClockGroup struct {
+	mu     sync.Mutex
+	master *Simulator
+	nodes  map[string]*Node
+}
+
+// NewClockGroup 返回以 master 为主时钟的 *ClockGroup
+func NewClockGroup(master *Simulator) *ClockGroup {
+	return &ClockGroup{
+		master: master,
+		nodes:  make(map[string]*Node),
+	}
+}
+
+// Master 返回主时钟，驱动它就能驱动所有的节点
+func (g *ClockGroup) Master() *Simulator {
+	return g.master
+}
+
+// Node 返回名为 name 的节点时钟。
+// 节点不存在时，会新建一个与主时钟一致的节点。
+func (g *ClockGroup) Node(name string) *Node {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if n, ok := g.nodes[name]; ok {
+		return n
+	}
+	now := g.master.Now()
+	n := &Node{
+		name:         name,
+		master:       g.master,
+		anchorMaster: now,
+		anchorLocal:  now,
+		rate:         1,
+	}
+	g.nodes[name] = n
+	return n
+}
+
+// Names 返回所有节点的名称，按字母顺序排列
+func (g *ClockGroup) Names() []string {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	names := make([]string, 0, len(g.nodes))
+	for name := range g.nodes {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Node 是 ClockGroup 中某个节点的时钟，实现了 Clock 接口。
+//
+// 节点的本地时间是主时钟墙上时间的线性函数
+//
+//	local = anchorLocal + (master - anchorMaster) * rate
+//
+// 改变偏移，漂移率或者跳变时，会以主时钟的当前时间重新设置锚点。
+//
+// NOTICE: timer 的到期时间在创建时就换算成了主时钟的时间，
+// 之后改变节点的漂移率或者跳变，不会影响已经创建的 timer。
+type Node struct {
+	mu     sync.Mutex
+	name   string
+	master *Simulator
+	// 主时钟的 anchorMaster 时刻，对应本地的 anchorLocal 时刻
+	anchorMaster time.Time
+	anchorLocal  time.Time
+	// rate 是本地时间相对于主时钟的流逝速度，等于 1 + ppm/1e6
+	rate float64
+}
+
+// Name 返回节点的名称
+func (n *Node) Name() string {
+	return n.name
+}
+
+// Offset 返回本地时间与主时钟时间的差
+func (n *Node) Offset() time.Duration {
+	m := n.master.Now()
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	return n.toLocal(m).Sub(m)
+}
+
+// SetOffset 让本地时间比主时钟快 d，d 为负数时，表示比主时钟慢。
+func (n *Node) SetOffset(d time.Duration) {
+	m := n.master.Now()
+	n.mu.Lock()
+	n.anchorMaster, n.anchorLocal = m, m.Add(d)
+	n.mu.Unlock()
+}
+
+// Drift 返回本地时钟的漂移率，单位是 ppm
+func (n *Node) Drift() float64 {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	return (n.rate - 1) * 1e6
+}
+
+// SetDrift 设置本地时钟的漂移率，单位是 ppm。
+// ppm 为正时，本地时钟走得比主时钟快。
+// ppm 必须大于 -1e6，否则会 panic
+func (n *Node) SetDrift(ppm float64) {
+	if ppm <= -1e6 {
+		panic("drift rate must be greater than -1e6 ppm")
+	}
+	m := n.master.Now()
+	n.mu.Lock()
+	n.anchorMaster, n.anchorLocal = m, n.toLocal(m)
+	n.rate = 1 + ppm/1e6
+	n.mu.Unlock()
+}
+
+// Step 让本地时间跳变 d，d 可以为负数。
+// 与 Simulator 不同，节点的本地时间是允许逆转的，
+// 这样才能模拟被 NTP 等手段调整过的机器时钟。
+func (n *Node) Step(d time.Duration) {
+	n.mu.Lock()
+	n.anchorLocal = n.anchorLocal.Add(d)
+	n.mu.Unlock()
+}
+
+// NOTICE: 以下的换算方法，务必在 n.mu 的临界区内运行
+
+func (n *Node) toLocal(m time.Time) time.Time {
+	return n.anchorLocal.Add(scale(m.Sub(n.anchorMaster), n.rate))
+}
+
+func (n *Node) toMaster(l time.Time) time.Time {
+	return n.anchorMaster.Add(scale(l.Sub(n.anchorLocal), 1/n.rate))
+}
+
+func (n *Node) masterDuration(d time.Duration) time.Duration {
+	return scale(d, 1/n.rate)
+}
+
+// localize 把主时钟的时间换算成本地时间
+// 会在主时钟的临界区内被调用
+func (n *Node) localize(m time.Time) time.Time {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	return n.toLocal(m)
+}
+
+func scale(d time.Duration, factor float64) time.Duration {
+	if factor == 1 {
+		return d
+	}
+	// 四舍五入，免得浮点误差让结果少了 1ns
+	return time.Duration(math.Round(float64(d) * factor))
+}
+
+// Now returns the current local time.
+func (n *Node) Now() time.Time {
+	m := n.master.Now()
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	return n.toLocal(m)
+}
+
+// Since returns the local time elapsed since t.
+func (n *Node) Since(t time.Time) time.Duration {
+	return n.Now().Sub(t)
+}
+
+// Until returns the local duration until t.
+func (n *Node) Until(t time.Time) time.Duration {
+	return t.Sub(n.Now())
+}
+
+// Sleep pauses the current goroutine for at least the local duration d.
+func (n *Node) Sleep(d time.Duration) {
+	if d <= 0 {
+		return
+	}
+	<-n.After(d)
+}
+
+// After waits for the local duration to elapse and then sends the local time on
+// the returned channel.
+func (n *Node) After(d time.Duration) <-chan time.Time {
+	return n.NewTimer(d).C
+}
+
+// AfterFunc waits for the local duration to elapse and then calls f in its own goroutine.
+func (n *Node) AfterFunc(d time.Duration, f func()) *Timer {
+	s := n.master
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return n.wrapTimer(s.newTimerFunc(n.fireAt(d), f))
+}
+
+// NewTimer creates a new Timer that will send the local time on its channel
+// after at least the local duration d.
+func (n *Node) NewTimer(d time.Duration) *Timer {
+	s := n.master
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return n.wrapTimer(s.newLocalTimerFunc(n.fireAt(d), nil, n.localize))
+}
+
+// NOTICE: 务必在主时钟的临界区内运行此方法
+func (n *Node) fireAt(d time.Duration) time.Time {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	return n.master.now.Add(n.masterDuration(d))
+}
+
+// wrapTimer 让 t.Reset 的参数使用本地时长
+func (n *Node) wrapTimer(t *Timer) *Timer {
+	reset := t.Reset
+	t.Reset = func(d time.Duration) bool {
+		n.mu.Lock()
+		md := n.masterDuration(d)
+		n.mu.Unlock()
+		return reset(md)
+	}
+	return t
+}
+
+// NewTicker returns a new Ticker containing a channel that will send the
+// local time with a period specified by the local duration d.
+func (n *Node) NewTicker(d time.Duration) *Ticker {
+	if d <= 0 {
+		panic("non-positive interval for NewTicker")
+	}
+	s := n.master
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.newLocalTicker(n.tickerPeriod(d), n.localize)
+}
+
+// Tick is a convenience wrapper for NewTicker providing access to the ticking
+// channel only.
+func (n *Node) Tick(d time.Duration) <-chan time.Time {
+	if d <= 0 {
+		return nil
+	}
+	s := n.master
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.newLocalTicker(n.tickerPeriod(d), n.localize).C
+}
+
+func (n *Node) tickerPeriod(d time.Duration) time.Duration {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	if md := n.masterDuration(d); md > 0 {
+		return md
+	}
+	return 1
+}
+
+// ContextWithDeadline implements Clock.
+// deadline 是本地时间
+func (n *Node) ContextWithDeadline(parent context.Context, deadline time.Time) (context.Context, context.CancelFunc) {
+	s := n.master
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	n.mu.Lock()
+	fireAt := s.monoOf(n.toMaster(deadline))
+	n.mu.Unlock()
+	return s.localContextWithDeadline(n, parent, deadline, fireAt)
+}
+
+// ContextWithTimeout implements Clock.
+// timeout 是本地时长
+func (n *Node) ContextWithTimeout(parent context.Context, timeout time.Duration) (context.Context, context.CancelFunc) {
+	s := n.master
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	n.mu.Lock()
+	deadline := n.toLocal(s.wallNow()).Add(timeout)
+	fireAt := s.now.Add(n.masterDuration(timeout))
+	n.mu.Unlock()
+	return s.localContextWithDeadline(n, parent, deadline, fireAt)
+}
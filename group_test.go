@@ -0,0 +1,170 @@
+package clock
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func Test_ClockGroup_Node(t *testing.T) {
+	Convey("新建一个 ClockGroup", t, func() {
+		now := time.Date(2020, 5, 20, 0, 0, 0, 0, time.UTC)
+		g := NewClockGroup(NewSimulator(now))
+		Convey("同名的节点是同一个", func() {
+			So(g.Node("a"), ShouldEqual, g.Node("a"))
+			So(g.Node("a").Name(), ShouldEqual, "a")
+		})
+		Convey("Names 按照字母顺序返回", func() {
+			g.Node("b")
+			g.Node("a")
+			So(g.Names(), ShouldResemble, []string{"a", "b"})
+		})
+		Convey("新节点与主时钟一致", func() {
+			n := g.Node("a")
+			So(n.Now(), ShouldEqual, now)
+			So(n.Offset(), ShouldEqual, 0)
+			So(n.Drift(), ShouldEqual, 0)
+			So(g.Master().Now(), ShouldEqual, now)
+		})
+	})
+}
+
+func Test_Node_time(t *testing.T) {
+	Convey("新建一个节点", t, func() {
+		now := time.Date(2020, 5, 20, 0, 0, 0, 0, time.UTC)
+		g := NewClockGroup(NewSimulator(now))
+		master := g.Master()
+		n := g.Node("a")
+		Convey("设置偏移后", func() {
+			n.SetOffset(time.Second)
+			So(n.Now(), ShouldEqual, now.Add(time.Second))
+			So(n.Offset(), ShouldEqual, time.Second)
+			Convey("偏移不随时间改变", func() {
+				master.Add(time.Hour)
+				So(n.Offset(), ShouldEqual, time.Second)
+			})
+		})
+		Convey("设置 1000ppm 的漂移后", func() {
+			n.SetDrift(1000)
+			So(n.Drift(), ShouldAlmostEqual, 1000, 1e-6)
+			master.Add(1000 * time.Second)
+			Convey("本地时间会多走 1s", func() {
+				So(n.Now(), ShouldEqual, now.Add(1001*time.Second))
+				So(n.Offset(), ShouldEqual, time.Second)
+			})
+			Convey("再次设置漂移，不会改变已有的偏移", func() {
+				n.SetDrift(0)
+				master.Add(1000 * time.Second)
+				So(n.Offset(), ShouldEqual, time.Second)
+			})
+		})
+		Convey("跳变可以让本地时间逆转", func() {
+			n.Step(-time.Minute)
+			So(n.Now(), ShouldEqual, now.Add(-time.Minute))
+			So(n.Since(now), ShouldEqual, -time.Minute)
+			So(n.Until(now), ShouldEqual, time.Minute)
+		})
+		Convey("非法的漂移率会 panic", func() {
+			So(func() { n.SetDrift(-1e6) }, ShouldPanic)
+		})
+	})
+}
+
+func Test_Node_timer(t *testing.T) {
+	Convey("两个漂移率不同的节点", t, func() {
+		now := time.Date(2020, 5, 20, 0, 0, 0, 0, time.UTC)
+		g := NewClockGroup(NewSimulator(now))
+		master := g.Master()
+		fast, slow := g.Node("fast"), g.Node("slow")
+		// fast 的 1s 是主时钟的 0.5s，slow 的 1s 是主时钟的 2s
+		fast.SetDrift(1e6)
+		slow.SetDrift(-0.5e6)
+		Convey("timer 按照主时钟的全局顺序触发", func() {
+			slowTimer := slow.NewTimer(time.Second)
+			fastTimer := fast.NewTimer(time.Second)
+			master.Move()
+			So(master.Now(), ShouldEqual, now.Add(500*time.Millisecond))
+			Convey("C 中发送的是本地时间", func() {
+				So(<-fastTimer.C, ShouldEqual, now.Add(time.Second))
+			})
+			master.Move()
+			So(master.Now(), ShouldEqual, now.Add(2*time.Second))
+			So(<-slowTimer.C, ShouldEqual, now.Add(time.Second))
+		})
+		Convey("Reset 使用本地时长", func() {
+			timer := fast.NewTimer(time.Hour)
+			So(timer.Reset(2*time.Second), ShouldBeTrue)
+			master.Move()
+			So(master.Now(), ShouldEqual, now.Add(time.Second))
+			So(<-timer.C, ShouldEqual, now.Add(2*time.Second))
+		})
+		Convey("AfterFunc 使用本地时长", func() {
+			var wg sync.WaitGroup
+			wg.Add(1)
+			slow.AfterFunc(time.Second, func() { wg.Done() })
+			master.Add(2 * time.Second)
+			wg.Wait()
+		})
+		Convey("Ticker 使用本地时长和本地时间", func() {
+			ticker := fast.NewTicker(time.Second)
+			defer ticker.Stop()
+			master.Move()
+			So(master.Now(), ShouldEqual, now.Add(500*time.Millisecond))
+			So(<-ticker.C, ShouldEqual, now.Add(time.Second))
+			So(fast.Tick(-time.Second), ShouldBeNil)
+			So(func() { fast.NewTicker(0) }, ShouldPanic)
+		})
+		Convey("Sleep 使用本地时长", func() {
+			go func() {
+				time.Sleep(10 * time.Millisecond)
+				master.Move()
+			}()
+			slow.Sleep(time.Second)
+			So(master.Now(), ShouldEqual, now.Add(2*time.Second))
+		})
+		Convey("Tick 与 After 也使用本地时长", func() {
+			tick := slow.Tick(time.Second)
+			after := fast.After(time.Second)
+			master.Add(2 * time.Second)
+			So(<-after, ShouldEqual, now.Add(time.Second))
+			So(<-tick, ShouldEqual, now.Add(time.Second))
+		})
+	})
+}
+
+func Test_Node_context(t *testing.T) {
+	Convey("有偏移的节点", t, func() {
+		now := time.Date(2020, 5, 20, 0, 0, 0, 0, time.UTC)
+		g := NewClockGroup(NewSimulator(now))
+		master := g.Master()
+		n := g.Node("a")
+		n.SetOffset(time.Hour)
+		Convey("ContextWithTimeout 的 Deadline 是本地时间", func() {
+			ctx, cancel := n.ContextWithTimeout(context.Background(), time.Second)
+			defer cancel()
+			deadline, ok := ctx.Deadline()
+			So(ok, ShouldBeTrue)
+			So(deadline, ShouldEqual, now.Add(time.Hour+time.Second))
+			Convey("上下文中的时钟就是节点", func() {
+				So(Get(ctx), ShouldEqual, n)
+			})
+			Convey("在主时钟的 1s 后到期", func() {
+				master.Add(time.Second)
+				<-ctx.Done()
+				So(ctx.Err(), ShouldNotBeNil)
+			})
+		})
+		Convey("ContextWithDeadline 使用本地时间", func() {
+			ctx, cancel := n.ContextWithDeadline(context.Background(), now.Add(time.Hour+time.Second))
+			defer cancel()
+			master.Add(time.Second - 1)
+			So(ctx.Err(), ShouldBeNil)
+			master.Add(1)
+			<-ctx.Done()
+			So(ctx.Err(), ShouldNotBeNil)
+		})
+	})
+}
@@ -17,9 +17,15 @@ type task struct {
 	// 用于替代 fire，
 	runFunc func(t *task) *task
 	index   int
+	// periodic 表示 task 触发后会重新放入 heap，例如 Ticker 和 EveryDay
+	periodic bool
 }
 
-const removed = -1
+const (
+	removed = -1
+	// registered 表示 task 还在 Simulator 的 shard 中，没有放入 heap
+	registered = -2
+)
 
 func newTask(deadline time.Time, run func(t *task) *task) *task {
 	return &task{
@@ -0,0 +1,102 @@
+// Package hlc 实现了基于 clock.Clock 的混合逻辑时钟（Hybrid Logical Clock）。
+//
+// 物理时间来自 clock.Clock 的 Now 方法，
+// 所以，在多个带有不同偏移的 *clock.Simulator 上运行 HLC，
+// 就可以确定性地测试时钟偏移的处理逻辑。
+//
+// 算法参考 https://cse.buffalo.edu/tech-reports/2014-04.pdf
+package hlc
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/jujili/clock"
+)
+
+// ErrClockDrift 表示远端时间戳超前本地物理时间太多
+var ErrClockDrift = errors.New("hlc: 远端时间戳超过了允许的最大偏移")
+
+// HLC 是混合逻辑时钟，可以安全地并发使用
+type HLC struct {
+	mu       sync.Mutex
+	clock    clock.Clock
+	maxDrift time.Duration
+	last     Timestamp
+}
+
+// NewHLC 返回以 c 为物理时钟的 *HLC
+// 默认不限制远端时间戳的偏移，可以使用 SetMaxDrift 设置。
+func NewHLC(c clock.Clock) *HLC {
+	return &HLC{clock: c}
+}
+
+// SetMaxDrift 设置远端时间戳最多可以超前本地物理时间多久。
+// d <= 0 表示不限制。
+func (h *HLC) SetMaxDrift(d time.Duration) {
+	h.mu.Lock()
+	h.maxDrift = d
+	h.mu.Unlock()
+}
+
+// Now 返回一个新的时间戳，用于本地事件或发送消息。
+// 返回值总是大于之前返回过的所有时间戳。
+func (h *HLC) Now() Timestamp {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	pt := h.physical()
+	if pt > h.last.WallTime {
+		h.last = Timestamp{WallTime: pt}
+	} else {
+		h.last.Logical++
+	}
+	return h.last
+}
+
+// Update 根据收到的远端时间戳 remote 推进本地时钟，并返回接收事件的时间戳。
+// 返回值总是大于 remote 和之前返回过的所有时间戳。
+//
+// 如果 remote 超前本地物理时间超过了 MaxDrift，
+// 本地时钟不会改变，并返回 ErrClockDrift。
+func (h *HLC) Update(remote Timestamp) (Timestamp, error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	pt := h.physical()
+	if h.maxDrift > 0 {
+		if drift := time.Duration(remote.WallTime - pt); drift > h.maxDrift {
+			return Timestamp{}, fmt.Errorf("%w: 超前了 %s，允许 %s", ErrClockDrift, drift, h.maxDrift)
+		}
+	}
+	last := h.last
+	switch {
+	case pt > last.WallTime && pt > remote.WallTime:
+		h.last = Timestamp{WallTime: pt}
+	case last.WallTime == remote.WallTime:
+		h.last.Logical = maxLogical(last.Logical, remote.Logical) + 1
+	case last.WallTime > remote.WallTime:
+		h.last.Logical++
+	default:
+		h.last = Timestamp{WallTime: remote.WallTime, Logical: remote.Logical + 1}
+	}
+	return h.last, nil
+}
+
+// Last 返回最近一次生成的时间戳，不会推进时钟
+func (h *HLC) Last() Timestamp {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return h.last
+}
+
+func (h *HLC) physical() int64 {
+	return h.clock.Now().UnixNano()
+}
+
+func maxLogical(a, b uint32) uint32 {
+	if a > b {
+		return a
+	}
+	return b
+}
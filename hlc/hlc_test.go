@@ -0,0 +1,77 @@
+package hlc
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/jujili/clock"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func Test_HLC_Now(t *testing.T) {
+	Convey("基于 Simulator 的 HLC", t, func() {
+		now := time.Date(2020, 5, 20, 0, 0, 0, 0, time.UTC)
+		s := clock.NewSimulator(now)
+		h := NewHLC(s)
+		Convey("物理时间前进时，逻辑计数归零", func() {
+			So(h.Now(), ShouldResemble, Timestamp{WallTime: now.UnixNano()})
+			s.Add(time.Nanosecond)
+			So(h.Now(), ShouldResemble, Timestamp{WallTime: now.UnixNano() + 1})
+		})
+		Convey("物理时间不变时，逻辑计数递增", func() {
+			first := h.Now()
+			second := h.Now()
+			So(first.Before(second), ShouldBeTrue)
+			So(second.Logical, ShouldEqual, 1)
+			So(h.Last(), ShouldResemble, second)
+		})
+	})
+}
+
+func Test_HLC_Update(t *testing.T) {
+	Convey("两个时钟偏移不同的节点", t, func() {
+		now := time.Date(2020, 5, 20, 0, 0, 0, 0, time.UTC)
+		skew := 100 * time.Millisecond
+		fast := NewHLC(clock.NewSimulator(now.Add(skew)))
+		slow := NewHLC(clock.NewSimulator(now))
+		Convey("慢节点收到快节点的消息后，时间戳依然有序", func() {
+			sent := fast.Now()
+			received, err := slow.Update(sent)
+			So(err, ShouldBeNil)
+			So(sent.Before(received), ShouldBeTrue)
+			So(received, ShouldResemble, Timestamp{WallTime: sent.WallTime, Logical: 1})
+			Convey("之后的本地事件也排在后面", func() {
+				So(received.Before(slow.Now()), ShouldBeTrue)
+			})
+		})
+		Convey("快节点收到慢节点的消息时，使用自己的物理时间", func() {
+			received, err := fast.Update(slow.Now())
+			So(err, ShouldBeNil)
+			So(received, ShouldResemble, Timestamp{WallTime: now.Add(skew).UnixNano()})
+		})
+		Convey("本地与远端的物理时间相同时，逻辑计数取最大值加一", func() {
+			local := slow.Now()
+			remote := Timestamp{WallTime: local.WallTime, Logical: 5}
+			received, _ := slow.Update(remote)
+			So(received, ShouldResemble, Timestamp{WallTime: local.WallTime, Logical: 6})
+		})
+		Convey("本地时间戳超前时，逻辑计数递增", func() {
+			fast.Update(Timestamp{WallTime: now.Add(time.Hour).UnixNano()})
+			received, _ := fast.Update(Timestamp{WallTime: now.UnixNano()})
+			So(received, ShouldResemble, Timestamp{WallTime: now.Add(time.Hour).UnixNano(), Logical: 2})
+		})
+		Convey("超过 MaxDrift 的远端时间戳会被拒绝", func() {
+			slow.SetMaxDrift(skew / 2)
+			before := slow.Last()
+			_, err := slow.Update(fast.Now())
+			So(errors.Is(err, ErrClockDrift), ShouldBeTrue)
+			So(slow.Last(), ShouldResemble, before)
+			Convey("在 MaxDrift 以内的就可以接受", func() {
+				slow.SetMaxDrift(skew)
+				_, err := slow.Update(fast.Now())
+				So(err, ShouldBeNil)
+			})
+		})
+	})
+}
@@ -0,0 +1,103 @@
+package hlc
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Timestamp 是混合逻辑时钟的时间戳。
+// WallTime 是物理时间部分，Logical 用于区分物理时间相同的事件。
+type Timestamp struct {
+	// WallTime 是 Unix 纪元以来的纳秒数
+	WallTime int64
+	Logical  uint32
+}
+
+// binaryLen 是 Timestamp 二进制编码的长度
+const binaryLen = 12
+
+// ErrInvalidEncoding 表示无法解码的 Timestamp
+var ErrInvalidEncoding = errors.New("hlc: 无效的 Timestamp 编码")
+
+// Time 返回 WallTime 对应的 time.Time
+func (t Timestamp) Time() time.Time {
+	return time.Unix(0, t.WallTime)
+}
+
+// IsZero 判断 t 是否是零值
+func (t Timestamp) IsZero() bool {
+	return t == Timestamp{}
+}
+
+// Compare 比较 t 与 u 的先后，
+// t 早于 u 返回 -1，晚于 u 返回 1，相等返回 0
+func (t Timestamp) Compare(u Timestamp) int {
+	switch {
+	case t.WallTime < u.WallTime:
+		return -1
+	case t.WallTime > u.WallTime:
+		return 1
+	case t.Logical < u.Logical:
+		return -1
+	case t.Logical > u.Logical:
+		return 1
+	}
+	return 0
+}
+
+// Before 判断 t 是否早于 u
+func (t Timestamp) Before(u Timestamp) bool {
+	return t.Compare(u) < 0
+}
+
+// String 返回 "WallTime.Logical" 形式的字符串
+func (t Timestamp) String() string {
+	return strconv.FormatInt(t.WallTime, 10) + "." + strconv.FormatUint(uint64(t.Logical), 10)
+}
+
+// MarshalText 实现了 encoding.TextMarshaler 接口，格式与 String 相同
+func (t Timestamp) MarshalText() ([]byte, error) {
+	return []byte(t.String()), nil
+}
+
+// UnmarshalText 实现了 encoding.TextUnmarshaler 接口
+func (t *Timestamp) UnmarshalText(text []byte) error {
+	parts := strings.SplitN(string(text), ".", 2)
+	if len(parts) != 2 {
+		return fmt.Errorf("%w: %q", ErrInvalidEncoding, text)
+	}
+	wall, err := strconv.ParseInt(parts[0], 10, 64)
+	if err != nil {
+		return fmt.Errorf("%w: %q", ErrInvalidEncoding, text)
+	}
+	logical, err := strconv.ParseUint(parts[1], 10, 32)
+	if err != nil {
+		return fmt.Errorf("%w: %q", ErrInvalidEncoding, text)
+	}
+	t.WallTime, t.Logical = wall, uint32(logical)
+	return nil
+}
+
+// MarshalBinary 实现了 encoding.BinaryMarshaler 接口
+// 编码是 12 个字节的大端序，
+// 对于非负的 WallTime，编码后的字节序与 Timestamp 的先后顺序一致。
+func (t Timestamp) MarshalBinary() ([]byte, error) {
+	b := make([]byte, binaryLen)
+	binary.BigEndian.PutUint64(b, uint64(t.WallTime))
+	binary.BigEndian.PutUint32(b[8:], t.Logical)
+	return b, nil
+}
+
+// UnmarshalBinary 实现了 encoding.BinaryUnmarshaler 接口
+func (t *Timestamp) UnmarshalBinary(data []byte) error {
+	if len(data) != binaryLen {
+		return fmt.Errorf("%w: 长度为 %d", ErrInvalidEncoding, len(data))
+	}
+	t.WallTime = int64(binary.BigEndian.Uint64(data))
+	t.Logical = binary.BigEndian.Uint32(data[8:])
+	return nil
+}
@@ -0,0 +1,67 @@
+package hlc
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func Test_Timestamp_Compare(t *testing.T) {
+	Convey("比较 Timestamp 的先后", t, func() {
+		a := Timestamp{WallTime: 10, Logical: 1}
+		So(a.Compare(Timestamp{WallTime: 11}), ShouldEqual, -1)
+		So(a.Compare(Timestamp{WallTime: 9, Logical: 5}), ShouldEqual, 1)
+		So(a.Compare(Timestamp{WallTime: 10, Logical: 2}), ShouldEqual, -1)
+		So(a.Compare(Timestamp{WallTime: 10}), ShouldEqual, 1)
+		So(a.Compare(a), ShouldEqual, 0)
+		So(a.Before(Timestamp{WallTime: 10, Logical: 2}), ShouldBeTrue)
+		So(Timestamp{}.IsZero(), ShouldBeTrue)
+		So(a.IsZero(), ShouldBeFalse)
+	})
+}
+
+func Test_Timestamp_Text(t *testing.T) {
+	Convey("文本编码", t, func() {
+		ts := Timestamp{WallTime: 1589980800000000000, Logical: 3}
+		text, err := ts.MarshalText()
+		So(err, ShouldBeNil)
+		So(string(text), ShouldEqual, "1589980800000000000.3")
+		Convey("可以解码回原值", func() {
+			var actual Timestamp
+			So(actual.UnmarshalText(text), ShouldBeNil)
+			So(actual, ShouldResemble, ts)
+		})
+		Convey("无效的编码会返回 ErrInvalidEncoding", func() {
+			var actual Timestamp
+			for _, s := range []string{"", "1", "a.1", "1.b", "1.-1"} {
+				err := actual.UnmarshalText([]byte(s))
+				So(errors.Is(err, ErrInvalidEncoding), ShouldBeTrue)
+			}
+		})
+	})
+}
+
+func Test_Timestamp_Binary(t *testing.T) {
+	Convey("二进制编码", t, func() {
+		ts := Timestamp{WallTime: 1589980800000000000, Logical: 3}
+		data, err := ts.MarshalBinary()
+		So(err, ShouldBeNil)
+		So(data, ShouldHaveLength, binaryLen)
+		Convey("可以解码回原值", func() {
+			var actual Timestamp
+			So(actual.UnmarshalBinary(data), ShouldBeNil)
+			So(actual, ShouldResemble, ts)
+		})
+		Convey("字节序与时间戳的先后一致", func() {
+			later, _ := Timestamp{WallTime: ts.WallTime, Logical: 4}.MarshalBinary()
+			So(bytes.Compare(data, later), ShouldEqual, -1)
+		})
+		Convey("长度不对会返回 ErrInvalidEncoding", func() {
+			var actual Timestamp
+			err := actual.UnmarshalBinary(data[1:])
+			So(errors.Is(err, ErrInvalidEncoding), ShouldBeTrue)
+		})
+	})
+}
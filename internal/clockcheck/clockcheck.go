@@ -0,0 +1,164 @@
+// Package clockcheck 是 clocklint 和 clockfix 共用的分析代码，
+// 用于找出直接使用 time 和 context 标准库中，依赖当前时间的函数的地方。
+//
+// 只使用 go/ast 和 go/types，不需要整个程序都能编译。
+package clockcheck
+
+import (
+	"go/ast"
+	"go/importer"
+	"go/token"
+	"go/types"
+	"path"
+	"strings"
+)
+
+// ClockPath 是 clock 包的导入路径
+const ClockPath = "github.com/jujili/clock"
+
+// Replacements 列出了需要替换的函数，以及 clock 包中对应的函数名。
+// time 包的函数，替换后需要在参数前面加上 ctx，
+// context 包的函数，替换后参数不变。
+var Replacements = map[string]string{
+	"time.Now":             "Now",
+	"time.Since":           "Since",
+	"time.Until":           "Until",
+	"time.Sleep":           "Sleep",
+	"time.After":           "After",
+	"time.AfterFunc":       "AfterFunc",
+	"time.NewTimer":        "NewTimer",
+	"time.NewTicker":       "NewTicker",
+	"time.Tick":            "Tick",
+	"context.WithTimeout":  "ContextWithTimeout",
+	"context.WithDeadline": "ContextWithDeadline",
+}
+
+// IgnoreDirective 放在行尾或上一行，可以忽略这一行
+const IgnoreDirective = "//clocklint:ignore"
+
+// Check 对属于同一个包的 files 进行类型检查。
+// 只有标准库会被真正导入，其他的包都用空包代替，所以类型错误都会被忽略。
+func Check(fset *token.FileSet, files []*ast.File) (*types.Package, *types.Info) {
+	info := &types.Info{
+		Uses: make(map[*ast.Ident]types.Object),
+		Defs: make(map[*ast.Ident]types.Object),
+	}
+	conf := types.Config{
+		Importer: stdImporter,
+		// 只关心 time 和 context 包，其他的类型错误都可以忽略
+		Error: func(error) {},
+	}
+	pkg, _ := conf.Check(files[0].Name.Name, fset, files, info)
+	return pkg, info
+}
+
+// StdFunc 返回 sel 引用的需要替换的函数名，例如 time.Now
+func StdFunc(info *types.Info, sel *ast.SelectorExpr) (string, bool) {
+	x, ok := sel.X.(*ast.Ident)
+	if !ok {
+		return "", false
+	}
+	// time.Time 的 After 方法等，不能算在内
+	if _, ok := info.Uses[x].(*types.PkgName); !ok {
+		return "", false
+	}
+	fn, ok := info.Uses[sel.Sel].(*types.Func)
+	if !ok || fn.Pkg() == nil {
+		return "", false
+	}
+	name := fn.Pkg().Path() + "." + fn.Name()
+	_, ok = Replacements[name]
+	return name, ok
+}
+
+// ReturnsTimer 报告 name 是不是返回 *time.Timer 或 *time.Ticker 的函数，
+// 替换后，返回的是 *clock.Timer 或 *clock.Ticker
+func ReturnsTimer(name string) bool {
+	switch name {
+	case "time.NewTimer", "time.AfterFunc", "time.NewTicker":
+		return true
+	}
+	return false
+}
+
+// IsContextFunc 报告 name 是不是 context 包的函数
+func IsContextFunc(name string) bool {
+	return strings.HasPrefix(name, "context.")
+}
+
+// ContextInScope 返回在 pos 处可以使用的 context.Context 变量，
+// 优先选择最内层作用域中的变量，同一作用域内优先选择 ctx。
+// 没有的话，返回 nil。
+func ContextInScope(pkg *types.Package, pos token.Pos) *types.Var {
+	if pkg == nil {
+		return nil
+	}
+	for s := pkg.Scope().Innermost(pos); s != nil; s = s.Parent() {
+		var found *types.Var
+		for _, name := range s.Names() {
+			v, ok := s.Lookup(name).(*types.Var)
+			if !ok || !isContext(v.Type()) {
+				continue
+			}
+			// 局部变量必须在 pos 之前声明
+			if s != pkg.Scope() && v.Pos() > pos {
+				continue
+			}
+			if name == "ctx" {
+				return v
+			}
+			if found == nil {
+				found = v
+			}
+		}
+		if found != nil {
+			return found
+		}
+	}
+	return nil
+}
+
+func isContext(t types.Type) bool {
+	named, ok := t.(*types.Named)
+	if !ok {
+		return false
+	}
+	obj := named.Obj()
+	return obj.Pkg() != nil && obj.Pkg().Path() == "context" && obj.Name() == "Context"
+}
+
+// IgnoredLines 返回 f 中被 IgnoreDirective 忽略的行号
+func IgnoredLines(fset *token.FileSet, f *ast.File) map[int]bool {
+	lines := make(map[int]bool)
+	for _, cg := range f.Comments {
+		for _, c := range cg.List {
+			if !strings.HasPrefix(c.Text, IgnoreDirective) {
+				continue
+			}
+			line := fset.Position(c.Slash).Line
+			lines[line] = true
+			lines[line+1] = true
+		}
+	}
+	return lines
+}
+
+// stdImporter 只导入标准库，其他的包都用空包代替，
+// 这样就不需要整个程序都能编译，也不需要依赖 go/packages
+var stdImporter = lenientImporter{importer.Default()}
+
+type lenientImporter struct {
+	types.Importer
+}
+
+func (im lenientImporter) Import(p string) (*types.Package, error) {
+	// 标准库的导入路径中，第一个元素不含有 .
+	if first := strings.SplitN(p, "/", 2)[0]; !strings.Contains(first, ".") {
+		if pkg, err := im.Importer.Import(p); err == nil {
+			return pkg, nil
+		}
+	}
+	pkg := types.NewPackage(p, path.Base(p))
+	pkg.MarkComplete()
+	return pkg, nil
+}
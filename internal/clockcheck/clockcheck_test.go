@@ -0,0 +1,62 @@
+package clockcheck
+
+import (
+	"go/ast"
+	"go/token"
+	"path/filepath"
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func Test_Check(t *testing.T) {
+	Convey("分析 testdata/a", t, func() {
+		fset := token.NewFileSet()
+		pkgs, err := LoadDir(fset, filepath.Join("testdata", "a"), false, nil)
+		So(err, ShouldBeNil)
+		So(len(pkgs), ShouldEqual, 1)
+		f := pkgs[0][0]
+		pkg, info := Check(fset, pkgs[0])
+		So(pkg.Name(), ShouldEqual, "a")
+		// 收集所有需要替换的 selector 所在的行
+		found := make(map[int]string)
+		scope := make(map[int]string)
+		local := make(map[int]bool)
+		ast.Inspect(f, func(n ast.Node) bool {
+			if sel, ok := n.(*ast.SelectorExpr); ok {
+				if name, ok := StdFunc(info, sel); ok {
+					line := fset.Position(sel.Pos()).Line
+					found[line] = name
+					if v := ContextInScope(pkg, sel.Pos()); v != nil {
+						scope[line] = v.Name()
+						local[line] = v.Parent() != pkg.Scope()
+					}
+				}
+			}
+			return true
+		})
+		Convey("StdFunc 只找出需要替换的函数", func() {
+			So(found, ShouldResemble, map[int]string{
+				11: "time.Now",
+				13: "time.Now",
+				15: "time.Now",
+				21: "time.Now",
+			})
+		})
+		Convey("ContextInScope 选择最内层的 context.Context 变量", func() {
+			So(scope[11], ShouldEqual, "parent")
+			So(scope[13], ShouldEqual, "ctx")
+			So(scope[21], ShouldEqual, "background")
+			So(local[13], ShouldBeTrue)
+			So(local[21], ShouldBeFalse)
+			So(ContextInScope(nil, f.Pos()), ShouldBeNil)
+		})
+		Convey("IgnoredLines", func() {
+			So(IgnoredLines(fset, f), ShouldResemble, map[int]bool{21: true, 22: true})
+		})
+		Convey("IsContextFunc", func() {
+			So(IsContextFunc("context.WithTimeout"), ShouldBeTrue)
+			So(IsContextFunc("time.Now"), ShouldBeFalse)
+		})
+	})
+}
@@ -0,0 +1,84 @@
+package clockcheck
+
+import (
+	"go/ast"
+	"go/build"
+	"go/parser"
+	"go/token"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Expand 把 dir/... 展开成 dir 下的所有目录，
+// 与 go 命令一样，会跳过 testdata，vendor，以及以 . 或 _ 开头的目录
+func Expand(patterns []string) ([]string, error) {
+	var dirs []string
+	for _, pattern := range patterns {
+		if pattern != "..." && !strings.HasSuffix(pattern, "/...") {
+			dirs = append(dirs, pattern)
+			continue
+		}
+		root := strings.TrimSuffix(strings.TrimSuffix(pattern, "..."), "/")
+		if root == "" {
+			root = "."
+		}
+		err := filepath.Walk(root, func(p string, info os.FileInfo, err error) error {
+			if err != nil {
+				return err
+			}
+			if !info.IsDir() {
+				return nil
+			}
+			name := info.Name()
+			if p != root && (name == "testdata" || name == "vendor" ||
+				strings.HasPrefix(name, ".") || strings.HasPrefix(name, "_")) {
+				return filepath.SkipDir
+			}
+			dirs = append(dirs, p)
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+	return dirs, nil
+}
+
+// LoadDir 解析目录 dir 中的 Go 文件，按照包分组返回。
+// 包内的测试文件与包在一组，外部测试包单独一组，
+// tests 为 false 时，不会解析测试文件。
+// skip 返回 true 的文件，不会被解析。
+func LoadDir(fset *token.FileSet, dir string, tests bool, skip func(name string) bool) ([][]*ast.File, error) {
+	bp, err := build.ImportDir(dir, 0)
+	if err != nil {
+		if _, ok := err.(*build.NoGoError); ok {
+			return nil, nil
+		}
+		return nil, err
+	}
+	groups := [][]string{append(bp.GoFiles, bp.CgoFiles...)}
+	if tests {
+		groups[0] = append(groups[0], bp.TestGoFiles...)
+		groups = append(groups, bp.XTestGoFiles)
+	}
+	var pkgs [][]*ast.File
+	for _, names := range groups {
+		var files []*ast.File
+		for _, name := range names {
+			name = filepath.Join(dir, name)
+			if skip != nil && skip(name) {
+				continue
+			}
+			f, err := parser.ParseFile(fset, name, nil, parser.ParseComments)
+			if err != nil {
+				return nil, err
+			}
+			files = append(files, f)
+		}
+		if len(files) > 0 {
+			pkgs = append(pkgs, files)
+		}
+	}
+	return pkgs, nil
+}
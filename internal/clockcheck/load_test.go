@@ -0,0 +1,65 @@
+package clockcheck
+
+import (
+	"go/token"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func Test_Expand(t *testing.T) {
+	Convey("展开目录", t, func() {
+		dirs, err := Expand([]string{"testdata/...", "."})
+		So(err, ShouldBeNil)
+		So(dirs, ShouldResemble, []string{
+			"testdata",
+			filepath.Join("testdata", "a"),
+			filepath.Join("testdata", "a", "sub"),
+			".",
+		})
+		Convey("跳过 testdata", func() {
+			dirs, err := Expand([]string{"./..."})
+			So(err, ShouldBeNil)
+			So(dirs, ShouldResemble, []string{"."})
+		})
+		Convey("目录不存在", func() {
+			_, err := Expand([]string{"nothing/..."})
+			So(err, ShouldNotBeNil)
+		})
+	})
+}
+
+func Test_LoadDir(t *testing.T) {
+	Convey("解析 testdata/a", t, func() {
+		fset := token.NewFileSet()
+		dir := filepath.Join("testdata", "a")
+		Convey("不包含测试文件", func() {
+			pkgs, err := LoadDir(fset, dir, false, nil)
+			So(err, ShouldBeNil)
+			So(len(pkgs), ShouldEqual, 1)
+			So(len(pkgs[0]), ShouldEqual, 1)
+		})
+		Convey("包含测试文件时，外部测试包单独一组", func() {
+			pkgs, err := LoadDir(fset, dir, true, nil)
+			So(err, ShouldBeNil)
+			So(len(pkgs), ShouldEqual, 2)
+			So(len(pkgs[0]), ShouldEqual, 2)
+			So(pkgs[1][0].Name.Name, ShouldEqual, "a_test")
+		})
+		Convey("跳过文件", func() {
+			pkgs, err := LoadDir(fset, dir, true, func(name string) bool {
+				return strings.HasSuffix(name, "_test.go")
+			})
+			So(err, ShouldBeNil)
+			So(len(pkgs), ShouldEqual, 1)
+			So(len(pkgs[0]), ShouldEqual, 1)
+		})
+		Convey("没有 Go 文件的目录", func() {
+			pkgs, err := LoadDir(fset, "testdata", true, nil)
+			So(err, ShouldBeNil)
+			So(pkgs, ShouldBeEmpty)
+		})
+	})
+}
@@ -0,0 +1,22 @@
+package a
+
+import (
+	"context"
+	"time"
+)
+
+var background = context.Background()
+
+func f(parent context.Context) bool {
+	_ = time.Now()
+	ctx := parent
+	_ = time.Now()
+	_ = ctx
+	return t.After(time.Now())
+}
+
+var t time.Time
+
+func g() {
+	_ = time.Now() //clocklint:ignore
+}
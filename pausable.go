@@ -0,0 +1,260 @@
+package clock
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// PausableClock 跟随真实时间流逝，但是可以被暂停：
+//
+//   - Pause 以后，时间停止，所有的 Timer，Ticker 和上下文都不再前进
+//   - Resume 以后，时间从暂停的时刻继续流逝
+//   - 暂停期间，可以使用 Step 手动前进
+//
+// 适合交互式地调试长时间运行的服务，以及游戏服务器。
+//
+// 任务由内部的 *Simulator 管理，后台的 goroutine 使用真实的 timer，
+// 在下一个任务到期时，把 Simulator 推进到当前时间。
+// 不再使用时，请调用 Close 停止后台的 goroutine。
+//
+// NOTICE: 内部的 Simulator 不会调整墙上时间，所以其单调时间与墙上时间相同。
+type PausableClock struct {
+	mu sync.RWMutex
+	s  *Simulator
+	// 运行时，Now = base + time.Since(origin)
+	// origin 带有单调时钟读数，不受机器时钟跳变的影响
+	base   time.Time
+	origin time.Time
+	paused bool
+	// wake 在有新的任务，或者暂停状态改变时，唤醒后台的 goroutine
+	wake      chan struct{}
+	done      chan struct{}
+	closeOnce sync.Once
+}
+
+// NewPausableClock 返回从现在开始，跟随真实时间流逝的 *PausableClock
+func NewPausableClock() *PausableClock {
+	origin := time.Now()
+	s := NewSimulator(origin)
+	p := &PausableClock{
+		s:      s,
+		base:   s.MonotonicNow(),
+		origin: origin,
+		wake:   make(chan struct{}, 1),
+		done:   make(chan struct{}),
+	}
+	s.wake = p.wake
+	go p.loop()
+	return p
+}
+
+// loop 在下一个任务到期时，推进 p.s
+func (p *PausableClock) loop() {
+	for {
+		var alarm <-chan time.Time
+		var timer *time.Timer
+		if d, ok := p.next(); ok {
+			timer = time.NewTimer(d)
+			alarm = timer.C
+		}
+		select {
+		case <-alarm:
+		case <-p.wake:
+		case <-p.done:
+		}
+		if timer != nil {
+			timer.Stop()
+		}
+		select {
+		case <-p.done:
+			return
+		default:
+		}
+	}
+}
+
+// next 把 p.s 推进到当前时间，并返回下一个任务还需要等待的真实时长。
+// 暂停或者没有任务时，返回 false
+func (p *PausableClock) next() (time.Duration, bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.paused {
+		return 0, false
+	}
+	p.sync()
+	s := p.s
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.drain()
+	if !s.heap.hasTask() {
+		return 0, false
+	}
+	return (*s.heap)[0].deadline.Sub(s.now), true
+}
+
+// sync 把 p.s 推进到当前时间，触发此前到期的任务
+// NOTICE: 务必在 p.mu 的临界区内运行此方法
+func (p *PausableClock) sync() {
+	if p.paused {
+		return
+	}
+	p.s.Set(p.base.Add(time.Since(p.origin)))
+}
+
+// syncNow 加锁后，把 p.s 推进到当前时间
+func (p *PausableClock) syncNow() {
+	p.mu.Lock()
+	p.sync()
+	p.mu.Unlock()
+}
+
+// notify 唤醒后台的 goroutine
+func (p *PausableClock) notify() {
+	select {
+	case p.wake <- struct{}{}:
+	default:
+	}
+}
+
+// Pause 暂停时间，已经暂停的话，什么也不做
+func (p *PausableClock) Pause() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.paused {
+		return
+	}
+	p.sync()
+	p.paused = true
+	p.notify()
+}
+
+// Resume 让时间从暂停的时刻继续流逝，没有暂停的话，什么也不做
+func (p *PausableClock) Resume() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if !p.paused {
+		return
+	}
+	p.base = p.s.MonotonicNow()
+	p.origin = time.Now()
+	p.paused = false
+	p.notify()
+}
+
+// Paused 返回 p 是否处于暂停状态
+func (p *PausableClock) Paused() bool {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	return p.paused
+}
+
+// Step 在暂停期间，把时间前进 d，并触发此期间到期的任务。
+// d < 0 时，什么也不做。
+// 没有暂停时调用，会 panic
+func (p *PausableClock) Step(d time.Duration) time.Time {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if !p.paused {
+		panic("clock: Step 只能在 PausableClock 暂停时调用")
+	}
+	return p.s.Add(d)
+}
+
+// Close 停止后台的 goroutine，此后的 Timer，Ticker 和上下文都不会再到期。
+// 可以多次调用
+func (p *PausableClock) Close() {
+	p.closeOnce.Do(func() {
+		close(p.done)
+	})
+}
+
+// Now implements Clock.
+func (p *PausableClock) Now() time.Time {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	if p.paused {
+		return p.s.Now()
+	}
+	return p.base.Add(time.Since(p.origin))
+}
+
+// Since implements Clock.
+func (p *PausableClock) Since(t time.Time) time.Duration {
+	return p.Now().Sub(t)
+}
+
+// Until implements Clock.
+func (p *PausableClock) Until(t time.Time) time.Duration {
+	return t.Sub(p.Now())
+}
+
+// Sleep implements Clock.
+func (p *PausableClock) Sleep(d time.Duration) {
+	if d <= 0 {
+		return
+	}
+	<-p.After(d)
+}
+
+// After implements Clock.
+func (p *PausableClock) After(d time.Duration) <-chan time.Time {
+	return p.NewTimer(d).C
+}
+
+// NewTimer implements Clock.
+func (p *PausableClock) NewTimer(d time.Duration) *Timer {
+	p.syncNow()
+	return p.wrapTimer(p.s.NewTimer(d))
+}
+
+// AfterFunc implements Clock.
+func (p *PausableClock) AfterFunc(d time.Duration, f func()) *Timer {
+	p.syncNow()
+	return p.wrapTimer(p.s.AfterFunc(d, f))
+}
+
+// wrapTimer 让 t.Reset 从当前时间开始计算
+func (p *PausableClock) wrapTimer(t *Timer) *Timer {
+	reset := t.Reset
+	t.Reset = func(d time.Duration) bool {
+		p.syncNow()
+		return reset(d)
+	}
+	return t
+}
+
+// NewTicker implements Clock.
+func (p *PausableClock) NewTicker(d time.Duration) *Ticker {
+	if d <= 0 {
+		panic("non-positive interval for NewTicker")
+	}
+	p.syncNow()
+	return p.s.NewTicker(d)
+}
+
+// Tick implements Clock.
+func (p *PausableClock) Tick(d time.Duration) <-chan time.Time {
+	if d <= 0 {
+		return nil
+	}
+	return p.NewTicker(d).C
+}
+
+// ContextWithDeadline implements Clock.
+func (p *PausableClock) ContextWithDeadline(parent context.Context, deadline time.Time) (context.Context, context.CancelFunc) {
+	p.syncNow()
+	s := p.s
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.localContextWithDeadline(p, parent, deadline, s.monoOf(deadline))
+}
+
+// ContextWithTimeout implements Clock.
+func (p *PausableClock) ContextWithTimeout(parent context.Context, timeout time.Duration) (context.Context, context.CancelFunc) {
+	p.syncNow()
+	s := p.s
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.localContextWithDeadline(p, parent, s.wallNow().Add(timeout), s.now.Add(timeout))
+}
@@ -0,0 +1,97 @@
+package clock
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func Test_PausableClock(t *testing.T) {
+	Convey("跟随真实时间流逝的可暂停时钟", t, func() {
+		p := NewPausableClock()
+		defer p.Close()
+		Convey("运行时，Now 跟随真实时间", func() {
+			So(p.Paused(), ShouldBeFalse)
+			So(p.Now(), ShouldHappenWithin, 10*time.Millisecond, time.Now())
+			now := p.Now()
+			So(now, ShouldResemble, now.Round(0))
+		})
+		Convey("Timer 按照真实的时长到期", func() {
+			start := time.Now()
+			at := <-p.After(50 * time.Millisecond)
+			So(time.Since(start), ShouldBeGreaterThanOrEqualTo, 50*time.Millisecond)
+			So(at, ShouldHappenWithin, 20*time.Millisecond, time.Now())
+		})
+		Convey("暂停以后，时间停止，Timer 也不会到期", func() {
+			timer := p.NewTimer(20 * time.Millisecond)
+			p.Pause()
+			So(p.Paused(), ShouldBeTrue)
+			frozen := p.Now()
+			time.Sleep(50 * time.Millisecond)
+			So(p.Now(), ShouldEqual, frozen)
+			So(timer.C, ShouldBeEmpty)
+			Convey("Step 前进时间，并触发到期的任务", func() {
+				now := p.Step(20 * time.Millisecond)
+				So(now, ShouldEqual, frozen.Add(20*time.Millisecond))
+				So(p.Now(), ShouldEqual, now)
+				So((<-timer.C).After(now), ShouldBeFalse)
+			})
+			Convey("Resume 以后，时间从暂停的时刻继续", func() {
+				p.Resume()
+				So(p.Paused(), ShouldBeFalse)
+				So(p.Since(frozen), ShouldBeLessThan, 10*time.Millisecond)
+				<-timer.C
+				So(p.Since(frozen), ShouldBeGreaterThanOrEqualTo, 0)
+			})
+		})
+		Convey("没有暂停时，Step 会 panic", func() {
+			So(func() { p.Step(time.Second) }, ShouldPanic)
+		})
+		Convey("重复的 Pause 和 Resume 什么也不做", func() {
+			p.Pause()
+			p.Pause()
+			frozen := p.Now()
+			p.Resume()
+			p.Resume()
+			So(p.Since(frozen), ShouldBeLessThan, 10*time.Millisecond)
+		})
+		Convey("暂停期间，Ticker 只随着 Step 发送", func() {
+			p.Pause()
+			ticker := p.NewTicker(time.Hour)
+			defer ticker.Stop()
+			p.Step(time.Hour)
+			So(<-ticker.C, ShouldEqual, p.Now())
+			So(func() { p.NewTicker(0) }, ShouldPanic)
+			So(p.Tick(0), ShouldBeNil)
+		})
+		Convey("暂停期间，上下文不会到期", func() {
+			ctx, cancel := p.ContextWithTimeout(context.Background(), 20*time.Millisecond)
+			defer cancel()
+			p.Pause()
+			time.Sleep(50 * time.Millisecond)
+			So(ctx.Err(), ShouldBeNil)
+			So(Now(ctx), ShouldEqual, p.Now())
+			p.Step(20 * time.Millisecond)
+			<-ctx.Done()
+			So(errors.Is(ctx.Err(), context.DeadlineExceeded), ShouldBeTrue)
+		})
+		Convey("Reset 从当前时间开始计算", func() {
+			fired := make(chan struct{})
+			timer := p.AfterFunc(time.Hour, func() { close(fired) })
+			p.Pause()
+			p.Step(30 * time.Minute)
+			So(timer.Reset(time.Hour), ShouldBeTrue)
+			p.Step(30 * time.Minute)
+			select {
+			case <-fired:
+				t.Error("Reset 以后，依然在原来的时间到期")
+			case <-time.After(10 * time.Millisecond):
+			}
+			p.Step(30 * time.Minute)
+			<-fired
+		})
+	})
+}
@@ -0,0 +1,187 @@
+package clock
+
+import (
+	"time"
+)
+
+// Recurring 描述了周期性出现的时刻，例如：每周一的 09:00
+type Recurring interface {
+	// Next 返回 t 之后（不含 t）的下一个时刻
+	Next(t time.Time) time.Time
+}
+
+// clockTime 是一天中的钟面时间
+type clockTime struct {
+	hour, minute, second int
+}
+
+// on 返回 loc 时区中 y-m-d 的钟面时间 ct
+func (ct clockTime) on(y int, m time.Month, d int, loc *time.Location) time.Time {
+	return time.Date(y, m, d, ct.hour, ct.minute, ct.second, 0, loc)
+}
+
+// locationOrLocal 在 loc 为 nil 时，返回 time.Local
+func locationOrLocal(loc *time.Location) *time.Location {
+	if loc == nil {
+		return time.Local
+	}
+	return loc
+}
+
+// daysIn 返回 y 年 m 月的天数
+func daysIn(y int, m time.Month) int {
+	return time.Date(y, m+1, 0, 0, 0, 0, 0, time.UTC).Day()
+}
+
+type daily struct {
+	loc *time.Location
+	at  clockTime
+}
+
+// Daily 返回 loc 时区中，每天 hour:minute:second 的 Recurring。
+// loc 为 nil 时，使用 time.Local
+// NOTICE: hour 是 24 小时制的小时
+func Daily(loc *time.Location, hour, minute, second int) Recurring {
+	return daily{loc: locationOrLocal(loc), at: clockTime{hour, minute, second}}
+}
+
+func (r daily) Next(t time.Time) time.Time {
+	y, m, d := t.In(r.loc).Date()
+	next := r.at.on(y, m, d, r.loc)
+	if !next.After(t) {
+		next = r.at.on(y, m, d+1, r.loc)
+	}
+	return next
+}
+
+type weekly struct {
+	loc     *time.Location
+	weekday time.Weekday
+	at      clockTime
+}
+
+// Weekly 返回 loc 时区中，每周 weekday 的 hour:minute:second 的 Recurring。
+// loc 为 nil 时，使用 time.Local
+func Weekly(loc *time.Location, weekday time.Weekday, hour, minute, second int) Recurring {
+	return weekly{loc: locationOrLocal(loc), weekday: weekday, at: clockTime{hour, minute, second}}
+}
+
+func (r weekly) Next(t time.Time) time.Time {
+	local := t.In(r.loc)
+	y, m, d := local.Date()
+	d += (int(r.weekday) - int(local.Weekday()) + 7) % 7
+	next := r.at.on(y, m, d, r.loc)
+	if !next.After(t) {
+		next = r.at.on(y, m, d+7, r.loc)
+	}
+	return next
+}
+
+// maxSearchMonths 是寻找下一个时刻时，最多检查的月数，
+// 每月 31 日和第 5 个星期几，都会在这么多个月内出现
+const maxSearchMonths = 12 * 4
+
+// monthly 在每个月中，由 day 选出一天
+type monthly struct {
+	loc *time.Location
+	at  clockTime
+	// day 返回 y 年 m 月中选出的那一天，这个月没有的话，返回 0
+	day func(y int, m time.Month) int
+}
+
+func (r monthly) Next(t time.Time) time.Time {
+	y, m, _ := t.In(r.loc).Date()
+	for i := 0; i < maxSearchMonths; i++ {
+		// time.Date 会把 m+i 规范到正确的年份和月份
+		first := time.Date(y, m+time.Month(i), 1, 0, 0, 0, 0, r.loc)
+		if d := r.day(first.Year(), first.Month()); d > 0 {
+			if next := r.at.on(first.Year(), first.Month(), d, r.loc); next.After(t) {
+				return next
+			}
+		}
+	}
+	panic("clock: 找不到下一个时刻")
+}
+
+// Monthly 返回 loc 时区中，每月第 day 天的 hour:minute:second 的 Recurring。
+// day 为负数时，从月末开始数，-1 是每月的最后一天，-2 是倒数第二天。
+// 没有第 day 天的月份会被跳过，例如 day 为 31 时，跳过 4 月。
+// day 为 0 或者超出 [-31, 31] 的话，会 panic。
+// loc 为 nil 时，使用 time.Local
+func Monthly(loc *time.Location, day, hour, minute, second int) Recurring {
+	if day == 0 || day > 31 || day < -31 {
+		panic("clock: Monthly 的 day 必须在 [-31, -1] 或 [1, 31] 之内")
+	}
+	return monthly{
+		loc: locationOrLocal(loc),
+		at:  clockTime{hour, minute, second},
+		day: func(y int, m time.Month) int {
+			n := daysIn(y, m)
+			if day > 0 {
+				if day > n {
+					return 0
+				}
+				return day
+			}
+			if d := n + day + 1; d >= 1 {
+				return d
+			}
+			return 0
+		},
+	}
+}
+
+// NthWeekday 返回 loc 时区中，每月第 n 个 weekday 的 hour:minute:second 的 Recurring，
+// 例如：每月第二个星期二。
+// n 为负数时，从月末开始数，-1 是每月的最后一个 weekday。
+// 没有第 n 个 weekday 的月份会被跳过。
+// n 为 0 或者超出 [-5, 5] 的话，会 panic。
+// loc 为 nil 时，使用 time.Local
+func NthWeekday(loc *time.Location, n int, weekday time.Weekday, hour, minute, second int) Recurring {
+	if n == 0 || n > 5 || n < -5 {
+		panic("clock: NthWeekday 的 n 必须在 [-5, -1] 或 [1, 5] 之内")
+	}
+	return monthly{
+		loc: locationOrLocal(loc),
+		at:  clockTime{hour, minute, second},
+		day: func(y int, m time.Month) int {
+			last := daysIn(y, m)
+			var d int
+			if n > 0 {
+				first := time.Date(y, m, 1, 0, 0, 0, 0, time.UTC).Weekday()
+				d = 1 + (int(weekday)-int(first)+7)%7 + 7*(n-1)
+			} else {
+				lastWeekday := time.Date(y, m, last, 0, 0, 0, 0, time.UTC).Weekday()
+				d = last - (int(lastWeekday)-int(weekday)+7)%7 - 7*(-n-1)
+			}
+			if d < 1 || d > last {
+				return 0
+			}
+			return d
+		},
+	}
+}
+
+type interval struct {
+	start  time.Time
+	period time.Duration
+}
+
+// Interval 返回 start + k * period 的 Recurring，k 是整数。
+// 所有的时刻都与 start 对齐，例如：start 是某天的 00:00，period 是 15 分钟，
+// 那么时刻就是每个整点的 00，15，30 和 45 分，与从何时开始等待无关。
+// period 不是正数的话，会 panic
+func Interval(start time.Time, period time.Duration) Recurring {
+	if period <= 0 {
+		panic("non-positive interval for Interval")
+	}
+	return interval{start: start, period: period}
+}
+
+func (r interval) Next(t time.Time) time.Time {
+	if t.Before(r.start) {
+		return r.start
+	}
+	k := t.Sub(r.start)/r.period + 1
+	return r.start.Add(k * r.period)
+}
@@ -0,0 +1,103 @@
+package clock
+
+import (
+	"testing"
+	"time"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+// utc 返回 UTC 中的 y-m-d h:mi
+func utc(y int, m time.Month, d, h, mi int) time.Time {
+	return time.Date(y, m, d, h, mi, 0, 0, time.UTC)
+}
+
+func Test_Recurring(t *testing.T) {
+	Convey("Daily 返回 t 之后的每天的钟面时间", t, func() {
+		r := Daily(time.UTC, 9, 30, 0)
+		So(r.Next(utc(2024, 1, 31, 8, 0)), ShouldEqual, utc(2024, 1, 31, 9, 30))
+		So(r.Next(utc(2024, 1, 31, 9, 30)), ShouldEqual, utc(2024, 2, 1, 9, 30))
+		Convey("按照 loc 的时区计算", func() {
+			shanghai := time.FixedZone("CST", 8*60*60)
+			r := Daily(shanghai, 9, 0, 0)
+			// UTC 的 01:00 就是上海的 09:00
+			So(r.Next(utc(2024, 1, 31, 0, 0)), ShouldEqual, utc(2024, 1, 31, 1, 0))
+			So(Daily(nil, 9, 0, 0).Next(utc(2024, 1, 31, 0, 0)).Location(), ShouldEqual, time.Local)
+		})
+	})
+	Convey("Weekly 返回 t 之后的每周 weekday 的钟面时间", t, func() {
+		// 2024-01-03 是周三
+		r := Weekly(time.UTC, time.Wednesday, 9, 0, 0)
+		So(r.Next(utc(2024, 1, 3, 8, 0)), ShouldEqual, utc(2024, 1, 3, 9, 0))
+		So(r.Next(utc(2024, 1, 3, 9, 0)), ShouldEqual, utc(2024, 1, 10, 9, 0))
+		So(r.Next(utc(2024, 1, 1, 0, 0)), ShouldEqual, utc(2024, 1, 3, 9, 0))
+		So(r.Next(utc(2024, 1, 4, 0, 0)), ShouldEqual, utc(2024, 1, 10, 9, 0))
+		So(Weekly(time.UTC, time.Sunday, 0, 0, 0).Next(utc(2024, 12, 30, 0, 0)), ShouldEqual, utc(2025, 1, 5, 0, 0))
+	})
+	Convey("Monthly 返回 t 之后的每月第 day 天的钟面时间", t, func() {
+		Convey("没有第 31 天的月份被跳过", func() {
+			r := Monthly(time.UTC, 31, 0, 0, 0)
+			So(r.Next(utc(2024, 1, 15, 0, 0)), ShouldEqual, utc(2024, 1, 31, 0, 0))
+			So(r.Next(utc(2024, 1, 31, 0, 0)), ShouldEqual, utc(2024, 3, 31, 0, 0))
+			So(r.Next(utc(2024, 12, 31, 0, 0)), ShouldEqual, utc(2025, 1, 31, 0, 0))
+		})
+		Convey("-1 是每月的最后一天", func() {
+			r := Monthly(time.UTC, -1, 18, 0, 0)
+			So(r.Next(utc(2024, 1, 31, 18, 0)), ShouldEqual, utc(2024, 2, 29, 18, 0))
+			So(r.Next(utc(2024, 2, 29, 18, 0)), ShouldEqual, utc(2024, 3, 31, 18, 0))
+			So(r.Next(utc(2023, 2, 1, 0, 0)), ShouldEqual, utc(2023, 2, 28, 18, 0))
+		})
+		Convey("-31 只出现在有 31 天的月份", func() {
+			r := Monthly(time.UTC, -31, 0, 0, 0)
+			So(r.Next(utc(2024, 1, 2, 0, 0)), ShouldEqual, utc(2024, 3, 1, 0, 0))
+		})
+		Convey("day 为 0 或者超出范围的话，会 panic", func() {
+			So(func() { Monthly(time.UTC, 0, 0, 0, 0) }, ShouldPanic)
+			So(func() { Monthly(time.UTC, 32, 0, 0, 0) }, ShouldPanic)
+			So(func() { Monthly(time.UTC, -32, 0, 0, 0) }, ShouldPanic)
+		})
+	})
+	Convey("NthWeekday 返回 t 之后的每月第 n 个 weekday 的钟面时间", t, func() {
+		Convey("每月第二个星期二", func() {
+			r := NthWeekday(time.UTC, 2, time.Tuesday, 10, 0, 0)
+			So(r.Next(utc(2024, 1, 1, 0, 0)), ShouldEqual, utc(2024, 1, 9, 10, 0))
+			So(r.Next(utc(2024, 1, 9, 10, 0)), ShouldEqual, utc(2024, 2, 13, 10, 0))
+		})
+		Convey("每月最后一个星期五", func() {
+			r := NthWeekday(time.UTC, -1, time.Friday, 17, 0, 0)
+			So(r.Next(utc(2024, 1, 1, 0, 0)), ShouldEqual, utc(2024, 1, 26, 17, 0))
+			So(r.Next(utc(2024, 1, 26, 17, 0)), ShouldEqual, utc(2024, 2, 23, 17, 0))
+			So(r.Next(utc(2024, 5, 1, 0, 0)), ShouldEqual, utc(2024, 5, 31, 17, 0))
+		})
+		Convey("没有第五个星期一的月份被跳过", func() {
+			r := NthWeekday(time.UTC, 5, time.Monday, 0, 0, 0)
+			So(r.Next(utc(2024, 1, 1, 0, 0)), ShouldEqual, utc(2024, 1, 29, 0, 0))
+			So(r.Next(utc(2024, 1, 29, 0, 0)), ShouldEqual, utc(2024, 4, 29, 0, 0))
+		})
+		Convey("n 为 0 或者超出范围的话，会 panic", func() {
+			So(func() { NthWeekday(time.UTC, 0, time.Monday, 0, 0, 0) }, ShouldPanic)
+			So(func() { NthWeekday(time.UTC, 6, time.Monday, 0, 0, 0) }, ShouldPanic)
+			So(func() { NthWeekday(time.UTC, -6, time.Monday, 0, 0, 0) }, ShouldPanic)
+		})
+	})
+	Convey("Interval 返回与 start 对齐的时刻", t, func() {
+		start := utc(2024, 1, 1, 0, 0)
+		r := Interval(start, 15*time.Minute)
+		So(r.Next(start.Add(-time.Hour)), ShouldEqual, start)
+		So(r.Next(start), ShouldEqual, utc(2024, 1, 1, 0, 15))
+		So(r.Next(utc(2024, 1, 1, 10, 7)), ShouldEqual, utc(2024, 1, 1, 10, 15))
+		So(r.Next(utc(2024, 1, 1, 10, 15)), ShouldEqual, utc(2024, 1, 1, 10, 30))
+		So(func() { Interval(start, 0) }, ShouldPanic)
+	})
+	Convey("跨过夏令时切换时，钟面时间不变", t, func() {
+		loc, err := time.LoadLocation("America/New_York")
+		if err != nil {
+			return
+		}
+		// 2024-03-10 凌晨切换到夏令时
+		r := Daily(loc, 9, 0, 0)
+		next := r.Next(time.Date(2024, 3, 9, 10, 0, 0, 0, loc))
+		So(next, ShouldEqual, time.Date(2024, 3, 10, 9, 0, 0, 0, loc))
+		So(next.Sub(time.Date(2024, 3, 9, 9, 0, 0, 0, loc)), ShouldEqual, 23*time.Hour)
+	})
+}
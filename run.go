@@ -0,0 +1,129 @@
+package clock
+
+import (
+	"errors"
+	"runtime"
+	"time"
+)
+
+var (
+	// ErrRunLimit 表示 RunUntil 或 RunUntilIdle 触发的任务数达到了上限
+	ErrRunLimit = errors.New("clock: 触发的任务数达到了上限")
+	// ErrNoTask 表示 RunUntil 的条件还没有满足，Simulator 中就已经没有任务了
+	ErrNoTask = errors.New("clock: 没有可以触发的任务")
+)
+
+// RunStats 是 RunUntil，RunFor 和 RunUntilIdle 的运行统计
+type RunStats struct {
+	// Events 是触发的任务数，Ticker 的每一次触发都算一个
+	Events int
+	// Elapsed 是前进的虚拟时间（单调时间）
+	Elapsed time.Duration
+}
+
+// RunUntil 一个一个地触发任务，直到 cond 返回 true。
+// 每次触发后，都会让出执行权，让被唤醒的 goroutine 有机会运行，再检查 cond。
+//
+// 触发的任务数达到 limit 时，返回 ErrRunLimit；limit <= 0 表示没有上限。
+// 没有任务可以触发时，返回 ErrNoTask。
+// NOTICE: cond 会在每次触发后被调用，不要在其中做耗时或者会阻塞的操作。
+func (s *Simulator) RunUntil(cond func() bool, limit int) (RunStats, error) {
+	start := s.MonotonicNow()
+	var stats RunStats
+	for !cond() {
+		if limit > 0 && stats.Events >= limit {
+			return s.runStats(stats, start), ErrRunLimit
+		}
+		if !s.runNext(time.Time{}, false) {
+			// 让刚刚被唤醒的 goroutine 有机会放入新的任务
+			runtime.Gosched()
+			if cond() {
+				break
+			}
+			if !s.runNext(time.Time{}, false) {
+				return s.runStats(stats, start), ErrNoTask
+			}
+		}
+		stats.Events++
+		runtime.Gosched()
+	}
+	return s.runStats(stats, start), nil
+}
+
+// RunFor 把时间前进 d，与 Add 不同的是，
+// 每触发一个任务，都会让出执行权，让被唤醒的 goroutine 有机会运行，
+// 所以，这些 goroutine 在这段时间内新建的任务，也会被按时触发。
+// d < 0 时，什么也不做。
+func (s *Simulator) RunFor(d time.Duration) RunStats {
+	start := s.MonotonicNow()
+	var stats RunStats
+	if d < 0 {
+		return stats
+	}
+	end := start.Add(d)
+	for s.runNext(end, true) {
+		stats.Events++
+		runtime.Gosched()
+	}
+	return s.runStats(stats, start)
+}
+
+// RunUntilIdle 一个一个地触发任务，直到 Simulator 中只剩下 Ticker 和 EveryDay 这样的周期性任务。
+// 每次触发后，都会让出执行权，让被唤醒的 goroutine 有机会运行。
+// 周期性任务在此期间到期的话，也会被触发。
+//
+// 触发的任务数达到 limit 时，返回 ErrRunLimit；limit <= 0 表示没有上限。
+func (s *Simulator) RunUntilIdle(limit int) (RunStats, error) {
+	start := s.MonotonicNow()
+	var stats RunStats
+	for s.hasOneShotTask() {
+		if limit > 0 && stats.Events >= limit {
+			return s.runStats(stats, start), ErrRunLimit
+		}
+		if s.runNext(time.Time{}, false) {
+			stats.Events++
+		}
+		runtime.Gosched()
+	}
+	return s.runStats(stats, start), nil
+}
+
+// runNext 触发下一个任务，bounded 为 true 时，只触发 end 之前（含）到期的任务，
+// 没有这样的任务的话，把时间前进到 end。
+// 返回是否触发了任务。
+// NOTICE: 检查任务和前进到 end 必须在同一个临界区内，
+// 否则在两者之间放入的 end 之前到期的任务，会被跳过
+func (s *Simulator) runNext(end time.Time, bounded bool) bool {
+	s.mu.Lock()
+	st := s.newStep()
+	defer st.wait()
+	defer s.mu.Unlock()
+	s.drain()
+	if bounded && !s.heap.hasExpiredTask(end) {
+		s.setNowTo(end)
+		return false
+	}
+	if !s.heap.hasTask() {
+		return false
+	}
+	s.accomplishNextTask(st)
+	return true
+}
+
+// hasOneShotTask 报告 heap 中是否还有非周期性的任务
+func (s *Simulator) hasOneShotTask() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.drain()
+	for _, t := range *s.heap {
+		if !t.periodic {
+			return true
+		}
+	}
+	return false
+}
+
+func (s *Simulator) runStats(stats RunStats, start time.Time) RunStats {
+	stats.Elapsed = s.MonotonicNow().Sub(start)
+	return stats
+}
@@ -0,0 +1,121 @@
+package clock
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func Test_Simulator_RunUntil(t *testing.T) {
+	Convey("Simulator.RunUntil", t, func() {
+		now := time.Date(2020, 5, 20, 0, 0, 0, 0, time.UTC)
+		s := NewSimulator(now)
+		Convey("触发任务，直到条件满足", func() {
+			var count int32
+			go func() {
+				for i := 0; i < 3; i++ {
+					s.Sleep(time.Second)
+					atomic.AddInt32(&count, 1)
+				}
+			}()
+			So(s.BlockUntil(context.Background(), 1), ShouldBeNil)
+			stats, err := s.RunUntil(func() bool { return atomic.LoadInt32(&count) == 3 }, 100)
+			So(err, ShouldBeNil)
+			So(stats.Events, ShouldEqual, 3)
+			So(stats.Elapsed, ShouldEqual, 3*time.Second)
+			So(s.Now(), ShouldEqual, now.Add(3*time.Second))
+		})
+		Convey("条件已经满足时，什么也不做", func() {
+			s.NewTimer(time.Second)
+			stats, err := s.RunUntil(func() bool { return true }, 0)
+			So(err, ShouldBeNil)
+			So(stats, ShouldResemble, RunStats{})
+		})
+		Convey("达到上限时，返回 ErrRunLimit", func() {
+			ticker := s.NewTicker(time.Second)
+			defer ticker.Stop()
+			stats, err := s.RunUntil(func() bool { return false }, 5)
+			So(err, ShouldEqual, ErrRunLimit)
+			So(stats.Events, ShouldEqual, 5)
+			So(stats.Elapsed, ShouldEqual, 5*time.Second)
+		})
+		Convey("没有任务时，返回 ErrNoTask", func() {
+			s.AfterFunc(time.Second, func() {})
+			stats, err := s.RunUntil(func() bool { return false }, 0)
+			So(err, ShouldEqual, ErrNoTask)
+			So(stats.Events, ShouldEqual, 1)
+		})
+	})
+}
+
+func Test_Simulator_RunFor(t *testing.T) {
+	Convey("Simulator.RunFor", t, func() {
+		now := time.Date(2020, 5, 20, 0, 0, 0, 0, time.UTC)
+		s := NewSimulator(now)
+		Convey("goroutine 在这段时间内新建的任务，也会被触发", func() {
+			done := make(chan struct{})
+			go func() {
+				for i := 0; i < 3; i++ {
+					s.Sleep(time.Second)
+				}
+				close(done)
+			}()
+			So(s.BlockUntil(context.Background(), 1), ShouldBeNil)
+			stats := s.RunFor(10 * time.Second)
+			So(stats.Elapsed, ShouldEqual, 10*time.Second)
+			So(s.Now(), ShouldEqual, now.Add(10*time.Second))
+			// 让出执行权后，goroutine 不一定来得及放入下一个任务，所以至少触发了 1 个
+			So(stats.Events, ShouldBeGreaterThanOrEqualTo, 1)
+		})
+		Convey("只触发到期的任务", func() {
+			s.NewTimer(time.Second)
+			s.NewTimer(3 * time.Second)
+			stats := s.RunFor(2 * time.Second)
+			So(stats.Events, ShouldEqual, 1)
+			So(len(s.Pending()), ShouldEqual, 1)
+		})
+		Convey("没有任务时，时间依然前进 d", func() {
+			stats := s.RunFor(time.Minute)
+			So(stats, ShouldResemble, RunStats{Elapsed: time.Minute})
+			So(s.Now(), ShouldEqual, now.Add(time.Minute))
+		})
+		Convey("d < 0 时，什么也不做", func() {
+			So(s.RunFor(-time.Second), ShouldResemble, RunStats{})
+			So(s.Now(), ShouldEqual, now)
+		})
+	})
+}
+
+func Test_Simulator_RunUntilIdle(t *testing.T) {
+	Convey("Simulator.RunUntilIdle", t, func() {
+		now := time.Date(2020, 5, 20, 0, 0, 0, 0, time.UTC)
+		s := NewSimulator(now)
+		Convey("触发所有非周期性的任务", func() {
+			ticker := s.NewTicker(time.Second)
+			defer ticker.Stop()
+			s.NewTimer(1500 * time.Millisecond)
+			s.AfterFunc(3*time.Second, func() {})
+			stats, err := s.RunUntilIdle(0)
+			So(err, ShouldBeNil)
+			// 1s, 1.5s, 2s, 3s 的 ticker 和 3s 的 AfterFunc，顺序不确定
+			So(stats.Events, ShouldBeBetweenOrEqual, 4, 5)
+			So(stats.Elapsed, ShouldEqual, 3*time.Second)
+			So(len(s.Pending()), ShouldEqual, 1)
+		})
+		Convey("达到上限时，返回 ErrRunLimit", func() {
+			s.NewTimer(time.Second)
+			s.NewTimer(2 * time.Second)
+			stats, err := s.RunUntilIdle(1)
+			So(err, ShouldEqual, ErrRunLimit)
+			So(stats.Events, ShouldEqual, 1)
+		})
+		Convey("没有任务时，立即返回", func() {
+			stats, err := s.RunUntilIdle(0)
+			So(err, ShouldBeNil)
+			So(stats, ShouldResemble, RunStats{})
+		})
+	})
+}
@@ -0,0 +1,175 @@
+package clock
+
+import (
+	"context"
+	"math"
+	"sync"
+	"time"
+)
+
+// scaledClock 跟随真实时间流逝，但是
+//
+//	Now = start + (time.Now() - origin) * scale
+//
+// scale 为 1 时，就是一个有固定偏移的真实时钟。
+//
+// NOTICE: 所有的 timer 都由 time 标准库实现，
+// 真实的等待时长是 d / scale。
+type scaledClock struct {
+	// origin 带有单调时钟读数，保证 Now 不会受到机器时钟跳变的影响
+	origin time.Time
+	start  time.Time
+	scale  float64
+}
+
+// NewOffsetClock 返回比真实时间快 offset 的时钟，
+// offset 为负数时，表示比真实时间慢。
+func NewOffsetClock(offset time.Duration) Clock {
+	origin := time.Now()
+	return &scaledClock{
+		origin: origin,
+		start:  origin.Add(offset).Round(0),
+		scale:  1,
+	}
+}
+
+// NewScaledClock 返回从 start 开始，以真实时间 scale 倍的速度流逝的时钟。
+// 比如，scale 为 60 时，真实时间每过 1 秒，时钟就过 1 分钟。
+// scale 必须大于 0，否则会 panic
+func NewScaledClock(start time.Time, scale float64) Clock {
+	if !(scale > 0) || math.IsInf(scale, 1) {
+		panic("scale must be positive")
+	}
+	return &scaledClock{
+		origin: time.Now(),
+		start:  start.Round(0),
+		scale:  scale,
+	}
+}
+
+// real 把时钟上的时长换算成真实的时长
+func (c *scaledClock) real(d time.Duration) time.Duration {
+	if c.scale == 1 {
+		return d
+	}
+	return time.Duration(math.Round(float64(d) / c.scale))
+}
+
+func (c *scaledClock) Now() time.Time {
+	elapsed := time.Since(c.origin)
+	if c.scale != 1 {
+		elapsed = time.Duration(math.Round(float64(elapsed) * c.scale))
+	}
+	return c.start.Add(elapsed)
+}
+
+func (c *scaledClock) Since(t time.Time) time.Duration {
+	return c.Now().Sub(t)
+}
+
+func (c *scaledClock) Until(t time.Time) time.Duration {
+	return t.Sub(c.Now())
+}
+
+func (c *scaledClock) Sleep(d time.Duration) {
+	time.Sleep(c.real(d))
+}
+
+func (c *scaledClock) After(d time.Duration) <-chan time.Time {
+	return c.NewTimer(d).C
+}
+
+func (c *scaledClock) AfterFunc(d time.Duration, f func()) *Timer {
+	t := time.AfterFunc(c.real(d), f)
+	return &Timer{
+		Stop:  t.Stop,
+		Reset: func(d time.Duration) bool { return t.Reset(c.real(d)) },
+		timer: t,
+	}
+}
+
+func (c *scaledClock) NewTimer(d time.Duration) *Timer {
+	ch := make(chan time.Time, 1)
+	t := time.AfterFunc(c.real(d), func() {
+		// 与 time.Timer 一样，能发送就发送
+		select {
+		case ch <- c.Now():
+		default:
+		}
+	})
+	return &Timer{
+		C:     ch,
+		Stop:  t.Stop,
+		Reset: func(d time.Duration) bool { return t.Reset(c.real(d)) },
+		timer: t,
+	}
+}
+
+func (c *scaledClock) NewTicker(d time.Duration) *Ticker {
+	if d <= 0 {
+		panic("non-positive interval for NewTicker")
+	}
+	period := c.real(d)
+	if period <= 0 {
+		period = 1
+	}
+	t := time.NewTicker(period)
+	ch := make(chan time.Time, 1)
+	done := make(chan struct{})
+	go func() {
+		for {
+			select {
+			case <-t.C:
+				select {
+				case ch <- c.Now():
+				default:
+				}
+			case <-done:
+				return
+			}
+		}
+	}()
+	var once sync.Once
+	return &Ticker{
+		C: ch,
+		Stop: func() {
+			once.Do(func() {
+				t.Stop()
+				close(done)
+			})
+		},
+		ticker: t,
+	}
+}
+
+func (c *scaledClock) Tick(d time.Duration) <-chan time.Time {
+	if d <= 0 {
+		return nil
+	}
+	return c.NewTicker(d).C
+}
+
+func (c *scaledClock) ContextWithDeadline(parent context.Context, deadline time.Time) (context.Context, context.CancelFunc) {
+	if pd, ok := parent.Deadline(); ok && !pd.After(deadline) {
+		// 与 context.WithDeadline 一样，父上下文更早到期的话，沿用父上下文的 deadline
+		return context.WithCancel(parent)
+	}
+	realDeadline := time.Now().Add(c.real(c.Until(deadline)))
+	ctx, cancel := context.WithDeadline(parent, realDeadline)
+	return &scaledContext{Context: ctx, deadline: deadline}, cancel
+}
+
+func (c *scaledClock) ContextWithTimeout(parent context.Context, timeout time.Duration) (context.Context, context.CancelFunc) {
+	return c.ContextWithDeadline(parent, c.Now().Add(timeout))
+}
+
+// scaledContext 由 context 标准库实现，
+// 只是 Deadline 返回的是时钟上的时间
+type scaledContext struct {
+	context.Context
+	deadline time.Time
+}
+
+func (ctx *scaledContext) Deadline() (time.Time, bool) {
+	return ctx.deadline, true
+}
@@ -0,0 +1,117 @@
+package clock
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func Test_NewOffsetClock(t *testing.T) {
+	Convey("比真实时间快一小时的时钟", t, func() {
+		c := NewOffsetClock(time.Hour)
+		Convey("Now 比 time.Now 快一小时", func() {
+			So(c.Now(), ShouldHappenWithin, 10*time.Millisecond, time.Now().Add(time.Hour))
+		})
+		Convey("Now 不带有单调时钟读数", func() {
+			now := c.Now()
+			So(now, ShouldResemble, now.Round(0))
+		})
+		Convey("Since 和 Until 使用偏移后的时间", func() {
+			So(c.Since(time.Now()), ShouldAlmostEqual, time.Hour, float64(10*time.Millisecond))
+			So(c.Until(time.Now()), ShouldAlmostEqual, -time.Hour, float64(10*time.Millisecond))
+		})
+		Convey("timer 的时长不受偏移影响", func() {
+			start := time.Now()
+			at := <-c.After(50 * time.Millisecond)
+			So(time.Since(start), ShouldBeGreaterThanOrEqualTo, 50*time.Millisecond)
+			So(at, ShouldHappenWithin, 10*time.Millisecond, time.Now().Add(time.Hour))
+		})
+		Convey("ContextWithDeadline 的 deadline 是偏移后的时间", func() {
+			deadline := c.Now().Add(50 * time.Millisecond)
+			ctx, cancel := c.ContextWithDeadline(context.Background(), deadline)
+			defer cancel()
+			d, ok := ctx.Deadline()
+			So(ok, ShouldBeTrue)
+			So(d, ShouldEqual, deadline)
+			select {
+			case <-ctx.Done():
+			case <-time.After(time.Second):
+			}
+			So(errors.Is(ctx.Err(), context.DeadlineExceeded), ShouldBeTrue)
+		})
+	})
+}
+
+func Test_NewScaledClock(t *testing.T) {
+	Convey("从 2020-05-20 开始，以 1000 倍速度流逝的时钟", t, func() {
+		start := time.Date(2020, 5, 20, 0, 0, 0, 0, time.UTC)
+		c := NewScaledClock(start, 1000)
+		Convey("scale 必须大于 0", func() {
+			So(func() { NewScaledClock(start, 0) }, ShouldPanic)
+			So(func() { NewScaledClock(start, -1) }, ShouldPanic)
+		})
+		Convey("真实时间过了 10ms，时钟过了 10s", func() {
+			time.Sleep(10 * time.Millisecond)
+			So(c.Since(start), ShouldBeGreaterThanOrEqualTo, 10*time.Second)
+			So(c.Since(start), ShouldBeLessThan, time.Minute)
+		})
+		Convey("Sleep 10s 只需要真实的 10ms", func() {
+			real := time.Now()
+			c.Sleep(10 * time.Second)
+			So(time.Since(real), ShouldBeLessThan, time.Second)
+			So(c.Since(start), ShouldBeGreaterThanOrEqualTo, 10*time.Second)
+		})
+		Convey("Timer 发送的是时钟上的时间", func() {
+			at := <-c.NewTimer(20 * time.Second).C
+			So(at.Sub(start), ShouldBeGreaterThanOrEqualTo, 20*time.Second)
+			So(at.Sub(start), ShouldBeLessThan, time.Minute+20*time.Second)
+		})
+		Convey("Reset 使用时钟上的时长", func() {
+			timer := c.NewTimer(time.Hour)
+			So(timer.Reset(10*time.Second), ShouldBeTrue)
+			select {
+			case <-timer.C:
+			case <-time.After(time.Second):
+				So("timer 没有触发", ShouldBeEmpty)
+			}
+		})
+		Convey("AfterFunc 可以被停止", func() {
+			timer := c.AfterFunc(time.Hour, func() {})
+			So(timer.Stop(), ShouldBeTrue)
+			So(timer.Stop(), ShouldBeFalse)
+		})
+		Convey("Ticker 以时钟上的周期发送", func() {
+			ticker := c.NewTicker(10 * time.Second)
+			first := <-ticker.C
+			second := <-ticker.C
+			ticker.Stop()
+			ticker.Stop()
+			So(second.Sub(first), ShouldBeGreaterThan, 0)
+			So(func() { c.NewTicker(0) }, ShouldPanic)
+			So(c.Tick(0), ShouldBeNil)
+			So(c.Tick(10*time.Second), ShouldNotBeNil)
+		})
+		Convey("ContextWithTimeout 在时钟上的时长后到期", func() {
+			real := time.Now()
+			ctx, cancel := c.ContextWithTimeout(context.Background(), 20*time.Second)
+			defer cancel()
+			d, _ := ctx.Deadline()
+			So(d.Sub(start), ShouldBeGreaterThanOrEqualTo, 20*time.Second)
+			<-ctx.Done()
+			So(time.Since(real), ShouldBeLessThan, time.Second)
+			So(errors.Is(ctx.Err(), context.DeadlineExceeded), ShouldBeTrue)
+		})
+		Convey("父上下文更早到期时，子上下文沿用父上下文的 deadline", func() {
+			parent, cancelParent := c.ContextWithTimeout(context.Background(), 20*time.Second)
+			defer cancelParent()
+			ctx, cancel := c.ContextWithTimeout(parent, time.Hour)
+			defer cancel()
+			pd, _ := parent.Deadline()
+			d, _ := ctx.Deadline()
+			So(d.Equal(pd), ShouldBeTrue)
+		})
+	})
+}
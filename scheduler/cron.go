@@ -0,0 +1,208 @@
+package scheduler
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// cron 是标准的 5 字段 cron 表达式
+//
+//	分钟 小时 日 月 星期
+//
+// 每个字段都是一个位图，第 i 位为 1 表示 i 符合要求。
+type cron struct {
+	minute, hour, dom, month, dow uint64
+	// 日和星期字段都不是 * 的时候，满足其中之一即可
+	// 否则需要同时满足
+	domStar, dowStar bool
+}
+
+type bounds struct {
+	min, max int
+}
+
+var (
+	minuteBounds = bounds{0, 59}
+	hourBounds   = bounds{0, 23}
+	domBounds    = bounds{1, 31}
+	monthBounds  = bounds{1, 12}
+	// 星期字段允许 7 表示星期日
+	dowBounds = bounds{0, 7}
+)
+
+var descriptors = map[string]string{
+	"@yearly":   "0 0 1 1 *",
+	"@annually": "0 0 1 1 *",
+	"@monthly":  "0 0 1 * *",
+	"@weekly":   "0 0 * * 0",
+	"@daily":    "0 0 * * *",
+	"@midnight": "0 0 * * *",
+	"@hourly":   "0 * * * *",
+}
+
+// Cron 解析标准的 5 字段 cron 表达式，返回对应的 Schedule
+//
+// 每个字段支持 *，数字，范围 a-b，步长 */n 和 a-b/n，以及用逗号分隔的列表。
+// 星期字段中，0 和 7 都表示星期日。
+// 还支持 @yearly，@monthly，@weekly，@daily 和 @hourly。
+//
+// 运行时间使用 Next 的输入参数所在的时区。
+func Cron(spec string) (Schedule, error) {
+	spec = strings.TrimSpace(spec)
+	if d, ok := descriptors[spec]; ok {
+		spec = d
+	}
+	fields := strings.Fields(spec)
+	if len(fields) != 5 {
+		return nil, fmt.Errorf("scheduler: cron 表达式 %q 需要 5 个字段", spec)
+	}
+	var c cron
+	var err error
+	if c.minute, err = parseField(fields[0], minuteBounds); err != nil {
+		return nil, err
+	}
+	if c.hour, err = parseField(fields[1], hourBounds); err != nil {
+		return nil, err
+	}
+	if c.dom, err = parseField(fields[2], domBounds); err != nil {
+		return nil, err
+	}
+	if c.month, err = parseField(fields[3], monthBounds); err != nil {
+		return nil, err
+	}
+	if c.dow, err = parseField(fields[4], dowBounds); err != nil {
+		return nil, err
+	}
+	// 把 7 合并到 0 中
+	if c.dow&(1<<7) != 0 {
+		c.dow |= 1
+		c.dow &^= 1 << 7
+	}
+	c.domStar = fields[2] == "*" || fields[2] == "?"
+	c.dowStar = fields[4] == "*" || fields[4] == "?"
+	return c, nil
+}
+
+// MustCron 与 Cron 一样，只是解析失败的时候会 panic
+func MustCron(spec string) Schedule {
+	s, err := Cron(spec)
+	if err != nil {
+		panic(err)
+	}
+	return s
+}
+
+func parseField(field string, b bounds) (uint64, error) {
+	var bits uint64
+	for _, part := range strings.Split(field, ",") {
+		r, err := parseRange(part, b)
+		if err != nil {
+			return 0, err
+		}
+		bits |= r
+	}
+	return bits, nil
+}
+
+// parseRange 解析 *，n，a-b，*/s 和 a-b/s
+func parseRange(part string, b bounds) (uint64, error) {
+	lo, hi, step := b.min, b.max, 1
+	rangeAndStep := strings.SplitN(part, "/", 2)
+	rng := rangeAndStep[0]
+	if rng != "*" && rng != "?" {
+		loHi := strings.SplitN(rng, "-", 2)
+		var err error
+		if lo, err = parseInt(loHi[0], b); err != nil {
+			return 0, err
+		}
+		hi = lo
+		if len(loHi) == 2 {
+			if hi, err = parseInt(loHi[1], b); err != nil {
+				return 0, err
+			}
+		}
+	}
+	if len(rangeAndStep) == 2 {
+		s, err := strconv.Atoi(rangeAndStep[1])
+		if err != nil || s <= 0 {
+			return 0, fmt.Errorf("scheduler: cron 字段 %q 的步长无效", part)
+		}
+		step = s
+		// n/s 表示从 n 开始，直到最大值
+		if rng != "*" && rng != "?" && !strings.Contains(rng, "-") {
+			hi = b.max
+		}
+	}
+	if lo > hi {
+		return 0, fmt.Errorf("scheduler: cron 字段 %q 的范围无效", part)
+	}
+	var bits uint64
+	for i := lo; i <= hi; i += step {
+		bits |= 1 << uint(i)
+	}
+	return bits, nil
+}
+
+func parseInt(s string, b bounds) (int, error) {
+	i, err := strconv.Atoi(s)
+	if err != nil {
+		return 0, fmt.Errorf("scheduler: cron 字段 %q 不是数字", s)
+	}
+	if i < b.min || b.max < i {
+		return 0, fmt.Errorf("scheduler: cron 字段 %d 超出了范围 [%d, %d]", i, b.min, b.max)
+	}
+	return i, nil
+}
+
+func has(bits uint64, i int) bool {
+	return bits&(1<<uint(i)) != 0
+}
+
+func (c cron) dayMatches(t time.Time) bool {
+	dom := has(c.dom, t.Day())
+	dow := has(c.dow, int(t.Weekday()))
+	if c.domStar || c.dowStar {
+		return dom && dow
+	}
+	return dom || dow
+}
+
+// Next 从 t 的下一分钟开始，依次从大到小地匹配 月，日，时，分。
+// 某个字段发生进位的时候，需要从头开始重新匹配。
+func (c cron) Next(t time.Time) time.Time {
+	loc := t.Location()
+	t = t.Truncate(time.Minute).Add(time.Minute)
+	// 5 年内都匹配不到的话，就认为永远匹配不到，比如 2 月 30 日
+	yearLimit := t.Year() + 5
+WRAP:
+	if t.Year() > yearLimit {
+		return time.Time{}
+	}
+	for !has(c.month, int(t.Month())) {
+		t = time.Date(t.Year(), t.Month()+1, 1, 0, 0, 0, 0, loc)
+		if t.Month() == time.January {
+			goto WRAP
+		}
+	}
+	for !c.dayMatches(t) {
+		t = time.Date(t.Year(), t.Month(), t.Day()+1, 0, 0, 0, 0, loc)
+		if t.Day() == 1 {
+			goto WRAP
+		}
+	}
+	for !has(c.hour, t.Hour()) {
+		t = time.Date(t.Year(), t.Month(), t.Day(), t.Hour()+1, 0, 0, 0, loc)
+		if t.Hour() == 0 {
+			goto WRAP
+		}
+	}
+	for !has(c.minute, t.Minute()) {
+		t = t.Add(time.Minute)
+		if t.Minute() == 0 {
+			goto WRAP
+		}
+	}
+	return t
+}
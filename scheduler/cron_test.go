@@ -0,0 +1,84 @@
+package scheduler
+
+import (
+	"testing"
+	"time"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func Test_Cron_parse(t *testing.T) {
+	Convey("解析 cron 表达式", t, func() {
+		Convey("合法的表达式", func() {
+			for _, spec := range []string{
+				"* * * * *",
+				"*/15 0-6/2 1,15 * 1-5",
+				"5 4 * * 7",
+				"@daily",
+				" @hourly ",
+			} {
+				_, err := Cron(spec)
+				So(err, ShouldBeNil)
+			}
+		})
+		Convey("非法的表达式", func() {
+			for _, spec := range []string{
+				"* * * *",
+				"60 * * * *",
+				"* 24 * * *",
+				"* * 0 * *",
+				"* * * 13 *",
+				"* * * * 8",
+				"*/0 * * * *",
+				"5-1 * * * *",
+				"a * * * *",
+			} {
+				_, err := Cron(spec)
+				So(err, ShouldNotBeNil)
+			}
+		})
+		Convey("MustCron 解析失败时会 panic", func() {
+			So(func() { MustCron("bad") }, ShouldPanic)
+		})
+	})
+}
+
+func Test_Cron_Next(t *testing.T) {
+	Convey("从 2020-05-20 13:14:15 (星期三) 开始", t, func() {
+		now := time.Date(2020, 5, 20, 13, 14, 15, 0, time.UTC)
+		next := func(spec string) time.Time {
+			return MustCron(spec).Next(now)
+		}
+		Convey("每分钟", func() {
+			So(next("* * * * *"), ShouldEqual, time.Date(2020, 5, 20, 13, 15, 0, 0, time.UTC))
+		})
+		Convey("每 15 分钟", func() {
+			So(next("*/15 * * * *"), ShouldEqual, time.Date(2020, 5, 20, 13, 15, 0, 0, time.UTC))
+		})
+		Convey("每天 0 点", func() {
+			So(next("@daily"), ShouldEqual, time.Date(2020, 5, 21, 0, 0, 0, 0, time.UTC))
+		})
+		Convey("每个月 1 日", func() {
+			So(next("@monthly"), ShouldEqual, time.Date(2020, 6, 1, 0, 0, 0, 0, time.UTC))
+		})
+		Convey("每周日，7 也表示周日", func() {
+			expected := time.Date(2020, 5, 24, 0, 0, 0, 0, time.UTC)
+			So(next("0 0 * * 0"), ShouldEqual, expected)
+			So(next("0 0 * * 7"), ShouldEqual, expected)
+		})
+		Convey("日和星期都有限制时，满足其一即可", func() {
+			So(next("0 0 1 * 5"), ShouldEqual, time.Date(2020, 5, 22, 0, 0, 0, 0, time.UTC))
+		})
+		Convey("闰年的 2 月 29 日", func() {
+			So(next("0 0 29 2 *"), ShouldEqual, time.Date(2024, 2, 29, 0, 0, 0, 0, time.UTC))
+		})
+		Convey("永远不存在的日期，返回零值", func() {
+			So(next("0 0 30 2 *").IsZero(), ShouldBeTrue)
+		})
+		Convey("使用输入时间的时区", func() {
+			loc := time.FixedZone("UTC+8", 8*60*60)
+			actual := MustCron("0 9 * * *").Next(now.In(loc))
+			So(actual, ShouldEqual, time.Date(2020, 5, 21, 9, 0, 0, 0, loc))
+		})
+	})
+}
@@ -0,0 +1,51 @@
+// Package scheduler 是基于 clock.Clock 的任务调度器。
+//
+// 调度器的所有计时都使用 clock.Clock，
+// 所以使用 *clock.Simulator 的时候，可以快进任意长的时间，
+// 再精确地断言每个任务在什么时刻运行过。
+package scheduler
+
+import "time"
+
+// Schedule 描述了任务的运行时间表
+type Schedule interface {
+	// Next 返回 t 之后的下一个运行时间。
+	// 返回零值表示不再运行。
+	Next(t time.Time) time.Time
+}
+
+// every 每隔固定的时长运行一次
+type every struct {
+	interval time.Duration
+}
+
+// Every 返回每隔 interval 运行一次的 Schedule
+// 第一次运行在添加任务的 interval 之后
+func Every(interval time.Duration) Schedule {
+	if interval <= 0 {
+		panic("non-positive interval for Every")
+	}
+	return every{interval: interval}
+}
+
+func (e every) Next(t time.Time) time.Time {
+	return t.Add(e.interval)
+}
+
+// once 只在 at 运行一次
+type once struct {
+	at time.Time
+}
+
+// At 返回只在 at 运行一次的 Schedule
+// 如果添加任务的时候，at 已经过去了，任务不会运行。
+func At(at time.Time) Schedule {
+	return once{at: at}
+}
+
+func (o once) Next(t time.Time) time.Time {
+	if t.Before(o.at) {
+		return o.at
+	}
+	return time.Time{}
+}
@@ -0,0 +1,32 @@
+package scheduler
+
+import (
+	"testing"
+	"time"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func Test_Every(t *testing.T) {
+	Convey("Every 的下一次运行时间", t, func() {
+		now := time.Date(2020, 5, 20, 13, 14, 0, 0, time.UTC)
+		So(Every(time.Hour).Next(now), ShouldEqual, now.Add(time.Hour))
+	})
+	Convey("非正时间的 Every 会 panic", t, func() {
+		So(func() { Every(0) }, ShouldPanicWith, "non-positive interval for Every")
+	})
+}
+
+func Test_At(t *testing.T) {
+	Convey("At 的下一次运行时间", t, func() {
+		at := time.Date(2020, 5, 20, 13, 14, 0, 0, time.UTC)
+		s := At(at)
+		Convey("在 at 之前，是 at", func() {
+			So(s.Next(at.Add(-time.Second)), ShouldEqual, at)
+		})
+		Convey("在 at 及其之后，是零值", func() {
+			So(s.Next(at).IsZero(), ShouldBeTrue)
+			So(s.Next(at.Add(time.Second)).IsZero(), ShouldBeTrue)
+		})
+	})
+}
@@ -0,0 +1,321 @@
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"math/rand"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/jujili/clock"
+)
+
+// CatchUp 决定了错过的运行时间该如何处理。
+// 调度器来不及运行的时候（比如：Simulator 一次快进了很久），
+// 就会出现多个已经过期的运行时间。
+type CatchUp int
+
+const (
+	// CatchUpAll 会补上每一次错过的运行
+	CatchUpAll CatchUp = iota
+	// CatchUpLatest 只运行最近的一次，其余的记录为跳过
+	CatchUpLatest
+	// CatchUpNone 跳过所有延误超过 Job.MaxDelay 的运行
+	CatchUpNone
+)
+
+var (
+	// ErrDuplicateJob 表示任务的名称已经存在
+	ErrDuplicateJob = errors.New("scheduler: 任务名称重复")
+	// ErrInvalidJob 表示任务缺少名称，Schedule 或 Run
+	ErrInvalidJob = errors.New("scheduler: 任务缺少 Name，Schedule 或 Run")
+)
+
+// Job 是需要被调度的任务
+type Job struct {
+	Name     string
+	Schedule Schedule
+	// Run 的 ctx 中已经放入了调度器的 Clock
+	// 可以直接使用 clock.Now(ctx) 等函数
+	Run func(ctx context.Context) error
+	// Jitter > 0 时，每次运行都会随机推迟 [0, Jitter) 的时间
+	Jitter time.Duration
+	// SkipIfRunning 为 true 时，如果上一次运行还没有结束，就跳过本次运行
+	SkipIfRunning bool
+	CatchUp       CatchUp
+	// MaxDelay 只在 CatchUp == CatchUpNone 时有效
+	MaxDelay time.Duration
+}
+
+// Record 是任务运行的历史记录
+type Record struct {
+	Job string
+	// Scheduled 是按照 Schedule 计划的运行时间，不含 Jitter
+	Scheduled time.Time
+	Started   time.Time
+	Finished  time.Time
+	Err       error
+	// Skipped 为 true 时，这次运行被跳过了，Started 和 Finished 都是零值
+	Skipped bool
+}
+
+// Config 是 Scheduler 的配置
+type Config struct {
+	// MaxConcurrency 是同时运行的任务的最大数量，<= 0 表示不限制
+	MaxConcurrency int
+	// HistorySize 是保留的历史记录的最大数量，<= 0 时使用 1024
+	HistorySize int
+	// Rand 用于生成 Jitter，为 nil 时使用 math/rand 的全局随机源
+	Rand *rand.Rand
+}
+
+type entry struct {
+	job Job
+	// next 是下一次计划的运行时间
+	next time.Time
+	// fireAt 是 next 加上 Jitter 后，实际的运行时间
+	fireAt  time.Time
+	running int
+}
+
+// Scheduler 按照各自的 Schedule 运行任务
+type Scheduler struct {
+	mu      sync.Mutex
+	clock   clock.Clock
+	config  Config
+	entries map[string]*entry
+	history []Record
+	// changed 用于通知 Run 重新计算等待时间
+	changed chan struct{}
+	sem     chan struct{}
+	wg      sync.WaitGroup
+}
+
+// New 返回基于 c 的时间线的 *Scheduler
+func New(c clock.Clock, config Config) *Scheduler {
+	if config.HistorySize <= 0 {
+		config.HistorySize = 1024
+	}
+	s := &Scheduler{
+		clock:   c,
+		config:  config,
+		entries: make(map[string]*entry),
+		changed: make(chan struct{}, 1),
+	}
+	if config.MaxConcurrency > 0 {
+		s.sem = make(chan struct{}, config.MaxConcurrency)
+	}
+	return s
+}
+
+// Add 添加任务，任务的第一次运行时间是 job.Schedule.Next(Now())
+func (s *Scheduler) Add(job Job) error {
+	if job.Name == "" || job.Schedule == nil || job.Run == nil {
+		return ErrInvalidJob
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.entries[job.Name]; ok {
+		return ErrDuplicateJob
+	}
+	e := &entry{job: job}
+	s.plan(e, job.Schedule.Next(s.clock.Now()))
+	if !e.next.IsZero() {
+		s.entries[job.Name] = e
+	}
+	s.notify()
+	return nil
+}
+
+// Remove 删除名为 name 的任务，正在运行的不受影响。
+// 返回 false 表示没有这个任务。
+func (s *Scheduler) Remove(name string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	_, ok := s.entries[name]
+	delete(s.entries, name)
+	s.notify()
+	return ok
+}
+
+// History 返回历史记录，由早到晚排列
+func (s *Scheduler) History() []Record {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	res := make([]Record, len(s.history))
+	copy(res, s.history)
+	return res
+}
+
+// Run 开始调度任务，直到 ctx 结束。
+// ctx 结束后，Run 不再启动新的任务，
+// 并等待所有正在运行的任务结束后，返回 ctx.Err()
+func (s *Scheduler) Run(ctx context.Context) error {
+	ctx = clock.Set(ctx, s.clock)
+	for {
+		var timer *clock.Timer
+		var fired <-chan time.Time
+		if at, ok := s.earliest(); ok {
+			d := s.clock.Until(at)
+			// Simulator 中已经过期的 timer，需要等到下一次驱动才会触发，
+			// 所以，直接处理已经过期的任务
+			if d <= 0 {
+				s.dispatch(ctx, s.clock.Now())
+				continue
+			}
+			timer = s.clock.NewTimer(d)
+			fired = timer.C
+		}
+		select {
+		case <-fired:
+			// 使用 Now 而不是 timer 发送的时间，
+			// 是因为 Simulator 有可能一次快进很久，
+			// 此时，需要把所有已经过期的运行时间，一次性处理完毕
+			s.dispatch(ctx, s.clock.Now())
+		case <-s.changed:
+		case <-ctx.Done():
+			if timer != nil {
+				timer.Stop()
+			}
+			s.wg.Wait()
+			return ctx.Err()
+		}
+		if timer != nil {
+			timer.Stop()
+		}
+	}
+}
+
+func (s *Scheduler) earliest() (time.Time, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var at time.Time
+	for _, e := range s.entries {
+		if at.IsZero() || e.fireAt.Before(at) {
+			at = e.fireAt
+		}
+	}
+	return at, !at.IsZero()
+}
+
+// dispatch 运行所有在 now 之前需要运行的任务
+func (s *Scheduler) dispatch(ctx context.Context, now time.Time) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	due := make([]*entry, 0, len(s.entries))
+	for _, e := range s.entries {
+		if !now.Before(e.fireAt) {
+			due = append(due, e)
+		}
+	}
+	// 保证同一时刻的多个任务，按照确定的顺序运行
+	sort.Slice(due, func(i, j int) bool {
+		if due[i].fireAt.Equal(due[j].fireAt) {
+			return due[i].job.Name < due[j].job.Name
+		}
+		return due[i].fireAt.Before(due[j].fireAt)
+	})
+	for _, e := range due {
+		times := dueTimes(e, now)
+		last := times[len(times)-1]
+		for i, t := range times {
+			if s.shouldSkip(e, t, now, i == len(times)-1) {
+				s.record(Record{Job: e.job.Name, Scheduled: t, Skipped: true})
+				continue
+			}
+			s.start(ctx, e, t)
+		}
+		s.plan(e, e.job.Schedule.Next(last))
+		if e.next.IsZero() {
+			delete(s.entries, e.job.Name)
+		}
+	}
+}
+
+// dueTimes 返回 e 在 now 之前所有需要运行的计划时间
+// 第一个计划时间带有 Jitter，其余的不考虑 Jitter
+func dueTimes(e *entry, now time.Time) []time.Time {
+	times := []time.Time{e.next}
+	for t := e.job.Schedule.Next(e.next); !t.IsZero() && !now.Before(t); t = e.job.Schedule.Next(t) {
+		times = append(times, t)
+	}
+	return times
+}
+
+// NOTICE: 务必在 s.mu 的临界区内运行此方法
+func (s *Scheduler) shouldSkip(e *entry, t, now time.Time, isLatest bool) bool {
+	if e.job.SkipIfRunning && e.running > 0 {
+		return true
+	}
+	switch e.job.CatchUp {
+	case CatchUpLatest:
+		return !isLatest
+	case CatchUpNone:
+		delay := now.Sub(t)
+		if t.Equal(e.next) {
+			delay = now.Sub(e.fireAt)
+		}
+		return delay > e.job.MaxDelay
+	}
+	return false
+}
+
+// NOTICE: 务必在 s.mu 的临界区内运行此方法
+func (s *Scheduler) start(ctx context.Context, e *entry, scheduled time.Time) {
+	e.running++
+	s.wg.Add(1)
+	go func() {
+		defer s.wg.Done()
+		if s.sem != nil {
+			s.sem <- struct{}{}
+			defer func() { <-s.sem }()
+		}
+		started := s.clock.Now()
+		err := e.job.Run(ctx)
+		finished := s.clock.Now()
+		s.mu.Lock()
+		e.running--
+		s.record(Record{
+			Job:       e.job.Name,
+			Scheduled: scheduled,
+			Started:   started,
+			Finished:  finished,
+			Err:       err,
+		})
+		s.mu.Unlock()
+	}()
+}
+
+// NOTICE: 务必在 s.mu 的临界区内运行此方法
+func (s *Scheduler) plan(e *entry, next time.Time) {
+	e.next = next
+	e.fireAt = next
+	if !next.IsZero() && e.job.Jitter > 0 {
+		e.fireAt = next.Add(s.jitter(e.job.Jitter))
+	}
+}
+
+func (s *Scheduler) jitter(max time.Duration) time.Duration {
+	if s.config.Rand == nil {
+		return time.Duration(rand.Int63n(int64(max)))
+	}
+	return time.Duration(s.config.Rand.Int63n(int64(max)))
+}
+
+// NOTICE: 务必在 s.mu 的临界区内运行此方法
+func (s *Scheduler) record(r Record) {
+	if len(s.history) == s.config.HistorySize {
+		copy(s.history, s.history[1:])
+		s.history = s.history[:len(s.history)-1]
+	}
+	s.history = append(s.history, r)
+}
+
+// NOTICE: 务必在 s.mu 的临界区内运行此方法
+func (s *Scheduler) notify() {
+	select {
+	case s.changed <- struct{}{}:
+	default:
+	}
+}
@@ -0,0 +1,265 @@
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"math/rand"
+	"sort"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/jujili/clock"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func nop(context.Context) error { return nil }
+
+// waitHistory 等待 s 中至少有 n 条历史记录
+// NOTICE: 任务是并发运行的，超时只是为了防止测试被永远阻塞
+func waitHistory(s *Scheduler, n int) []Record {
+	deadline := time.Now().Add(5 * time.Second)
+	for time.Now().Before(deadline) {
+		if h := s.History(); len(h) >= n {
+			return h
+		}
+		time.Sleep(time.Millisecond)
+	}
+	return s.History()
+}
+
+func scheduledOf(records []Record) []time.Time {
+	res := make([]time.Time, 0, len(records))
+	for _, r := range records {
+		res = append(res, r.Scheduled)
+	}
+	sort.Slice(res, func(i, j int) bool { return res[i].Before(res[j]) })
+	return res
+}
+
+func Test_Scheduler_Add(t *testing.T) {
+	Convey("新建一个 Scheduler", t, func() {
+		s := New(clock.NewSimulator(time.Now()), Config{})
+		job := Job{Name: "a", Schedule: Every(time.Hour), Run: nop}
+		Convey("添加任务", func() {
+			So(s.Add(job), ShouldBeNil)
+			Convey("重复的名称会返回 ErrDuplicateJob", func() {
+				So(s.Add(job), ShouldEqual, ErrDuplicateJob)
+			})
+			Convey("删除后，可以再次添加", func() {
+				So(s.Remove("a"), ShouldBeTrue)
+				So(s.Remove("a"), ShouldBeFalse)
+				So(s.Add(job), ShouldBeNil)
+			})
+		})
+		Convey("不完整的任务会返回 ErrInvalidJob", func() {
+			So(s.Add(Job{Name: "a", Run: nop}), ShouldEqual, ErrInvalidJob)
+			So(s.Add(Job{Schedule: Every(time.Hour), Run: nop}), ShouldEqual, ErrInvalidJob)
+			So(s.Add(Job{Name: "a", Schedule: Every(time.Hour)}), ShouldEqual, ErrInvalidJob)
+		})
+	})
+}
+
+func Test_Scheduler_Run(t *testing.T) {
+	Convey("在 Simulator 上运行 Scheduler", t, func() {
+		now := time.Date(2020, 5, 1, 0, 0, 0, 0, time.UTC)
+		sim := clock.NewSimulator(now)
+		s := New(sim, Config{HistorySize: 2000})
+		ctx, cancel := context.WithCancel(context.Background())
+		done := make(chan error)
+		go func() { done <- s.Run(ctx) }()
+		Convey("快进一个月，每小时的任务会运行 720 次", func() {
+			So(s.Add(Job{Name: "hourly", Schedule: Every(time.Hour), Run: nop}), ShouldBeNil)
+			// NOTICE: time.Sleep 是为了让 Run 有机会创建 timer
+			time.Sleep(10 * time.Millisecond)
+			sim.Add(30 * 24 * time.Hour)
+			records := waitHistory(s, 720)
+			So(records, ShouldHaveLength, 720)
+			scheduled := scheduledOf(records)
+			for i, at := range scheduled {
+				So(at, ShouldEqual, now.Add(time.Duration(i+1)*time.Hour))
+			}
+		})
+		Convey("逐步推进时，cron 任务会在精确的时刻运行", func() {
+			var mu sync.Mutex
+			var runs []time.Time
+			So(s.Add(Job{
+				Name:     "weekday",
+				Schedule: MustCron("30 9 * * 1-5"),
+				Run: func(ctx context.Context) error {
+					mu.Lock()
+					runs = append(runs, clock.Now(ctx))
+					mu.Unlock()
+					return nil
+				},
+			}), ShouldBeNil)
+			// 2020-05-01 是星期五
+			for i := 0; i < 3; i++ {
+				time.Sleep(10 * time.Millisecond)
+				sim.Move()
+				waitHistory(s, i+1)
+			}
+			mu.Lock()
+			defer mu.Unlock()
+			So(runs, ShouldResemble, []time.Time{
+				time.Date(2020, 5, 1, 9, 30, 0, 0, time.UTC),
+				time.Date(2020, 5, 4, 9, 30, 0, 0, time.UTC),
+				time.Date(2020, 5, 5, 9, 30, 0, 0, time.UTC),
+			})
+		})
+		Convey("ctx 结束后，会等待正在运行的任务结束", func() {
+			started := make(chan struct{})
+			So(s.Add(Job{
+				Name:     "slow",
+				Schedule: Every(time.Hour),
+				Run: func(ctx context.Context) error {
+					close(started)
+					<-ctx.Done()
+					return ctx.Err()
+				},
+			}), ShouldBeNil)
+			time.Sleep(10 * time.Millisecond)
+			sim.Move()
+			<-started
+			s.Remove("slow")
+			cancel()
+			So(errors.Is(<-done, context.Canceled), ShouldBeTrue)
+			records := s.History()
+			So(records, ShouldHaveLength, 1)
+			So(errors.Is(records[0].Err, context.Canceled), ShouldBeTrue)
+		})
+		Reset(func() {
+			cancel()
+			select {
+			case <-done:
+			case <-time.After(time.Second):
+			}
+		})
+	})
+}
+
+func Test_Scheduler_dispatch(t *testing.T) {
+	Convey("直接测试 dispatch", t, func() {
+		now := time.Date(2020, 5, 1, 0, 0, 0, 0, time.UTC)
+		sim := clock.NewSimulator(now)
+		s := New(sim, Config{})
+		ctx := context.Background()
+		later := now.Add(5*time.Hour + 30*time.Minute)
+		Convey("CatchUpAll 会补上所有错过的运行", func() {
+			s.Add(Job{Name: "a", Schedule: Every(time.Hour), Run: nop})
+			s.dispatch(ctx, later)
+			records := waitHistory(s, 5)
+			So(records, ShouldHaveLength, 5)
+			So(scheduledOf(records)[4], ShouldEqual, now.Add(5*time.Hour))
+			Convey("下一次运行时间在 later 之后", func() {
+				at, ok := s.earliest()
+				So(ok, ShouldBeTrue)
+				So(at, ShouldEqual, now.Add(6*time.Hour))
+			})
+		})
+		Convey("CatchUpLatest 只运行最近的一次", func() {
+			s.Add(Job{Name: "a", Schedule: Every(time.Hour), Run: nop, CatchUp: CatchUpLatest})
+			s.dispatch(ctx, later)
+			records := waitHistory(s, 5)
+			skipped := 0
+			for _, r := range records {
+				if r.Skipped {
+					skipped++
+					continue
+				}
+				So(r.Scheduled, ShouldEqual, now.Add(5*time.Hour))
+			}
+			So(skipped, ShouldEqual, 4)
+		})
+		Convey("CatchUpNone 跳过所有延误超过 MaxDelay 的运行", func() {
+			s.Add(Job{
+				Name:     "a",
+				Schedule: Every(time.Hour),
+				Run:      nop,
+				CatchUp:  CatchUpNone,
+				MaxDelay: 45 * time.Minute,
+			})
+			s.dispatch(ctx, later)
+			records := waitHistory(s, 5)
+			for _, r := range records {
+				So(r.Skipped, ShouldEqual, r.Scheduled.Before(now.Add(5*time.Hour)))
+			}
+		})
+		Convey("SkipIfRunning 会跳过上一次还没有结束的任务", func() {
+			release := make(chan struct{})
+			s.Add(Job{
+				Name:          "a",
+				Schedule:      Every(time.Hour),
+				SkipIfRunning: true,
+				Run: func(context.Context) error {
+					<-release
+					return nil
+				},
+			})
+			s.dispatch(ctx, now.Add(time.Hour))
+			s.dispatch(ctx, now.Add(2*time.Hour))
+			records := waitHistory(s, 1)
+			So(records, ShouldHaveLength, 1)
+			So(records[0].Skipped, ShouldBeTrue)
+			So(records[0].Scheduled, ShouldEqual, now.Add(2*time.Hour))
+			close(release)
+			So(waitHistory(s, 2), ShouldHaveLength, 2)
+		})
+		Convey("一次性的任务，运行后会被删除", func() {
+			s.Add(Job{Name: "once", Schedule: At(now.Add(time.Hour)), Run: nop})
+			s.dispatch(ctx, later)
+			So(waitHistory(s, 1), ShouldHaveLength, 1)
+			_, ok := s.earliest()
+			So(ok, ShouldBeFalse)
+		})
+	})
+}
+
+func Test_Scheduler_MaxConcurrency(t *testing.T) {
+	Convey("MaxConcurrency 为 1 时", t, func() {
+		now := time.Date(2020, 5, 1, 0, 0, 0, 0, time.UTC)
+		s := New(clock.NewSimulator(now), Config{MaxConcurrency: 1})
+		var mu sync.Mutex
+		running, maxRunning := 0, 0
+		run := func(context.Context) error {
+			mu.Lock()
+			running++
+			if running > maxRunning {
+				maxRunning = running
+			}
+			mu.Unlock()
+			time.Sleep(5 * time.Millisecond)
+			mu.Lock()
+			running--
+			mu.Unlock()
+			return nil
+		}
+		for _, name := range []string{"a", "b", "c"} {
+			s.Add(Job{Name: name, Schedule: Every(time.Hour), Run: run})
+		}
+		Convey("同时到期的任务，也只会一个一个地运行", func() {
+			s.dispatch(context.Background(), now.Add(time.Hour))
+			So(waitHistory(s, 3), ShouldHaveLength, 3)
+			So(maxRunning, ShouldEqual, 1)
+		})
+	})
+}
+
+func Test_Scheduler_Jitter(t *testing.T) {
+	Convey("带有 Jitter 的任务", t, func() {
+		now := time.Date(2020, 5, 1, 0, 0, 0, 0, time.UTC)
+		s := New(clock.NewSimulator(now), Config{Rand: rand.New(rand.NewSource(1))})
+		jitter := 10 * time.Minute
+		s.Add(Job{Name: "a", Schedule: Every(time.Hour), Run: nop, Jitter: jitter})
+		Convey("运行时间在 [next, next+Jitter) 之间", func() {
+			at, _ := s.earliest()
+			So(at, ShouldHappenOnOrAfter, now.Add(time.Hour))
+			So(at, ShouldHappenBefore, now.Add(time.Hour+jitter))
+			Convey("历史记录中的计划时间不含 Jitter", func() {
+				s.dispatch(context.Background(), at)
+				records := waitHistory(s, 1)
+				So(records[0].Scheduled, ShouldEqual, now.Add(time.Hour))
+			})
+		})
+	})
+}
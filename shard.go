@@ -0,0 +1,121 @@
+package clock
+
+import (
+	"runtime"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Simulator 的锁的设计
+//
+//   - 读取当前时间的 Now 和 MonotonicNow 不需要加锁，
+//     s.now 每次改变时，都会把单调时间和墙上时间一起发布到 s.snap 中。
+//   - 新建 Timer，Ticker 和 AfterFunc 也不需要加 s.mu，
+//     新的任务先放入 s.shards 中的一个，每个 shard 有自己的锁。
+//   - 驱动时钟，Stop 和 Reset 等需要访问 heap 的操作，仍然需要加 s.mu，
+//     它们会先调用 s.drain 把 shards 中的任务移入 heap。
+//
+// 加锁的顺序是 s.mu -> shard.mu -> s.acceptMu，不能反过来。
+
+// snapshot 是某一时刻的单调时间和墙上时间
+type snapshot struct {
+	mono, wall time.Time
+}
+
+// shard 暂存新建的任务
+type shard struct {
+	mu    sync.Mutex
+	tasks []*task
+	// 避免相邻的 shard 出现在同一个缓存行中
+	_ [32]byte
+}
+
+func newShards() []shard {
+	n := runtime.GOMAXPROCS(0)
+	if n < 1 {
+		n = 1
+	}
+	return make([]shard, n)
+}
+
+// publish 发布当前的时间
+// NOTICE: 务必在临界区内运行此方法，s.now 或墙上时间改变后，都要调用
+func (s *Simulator) publish() {
+	s.snap.Store(snapshot{mono: s.now, wall: s.wallNow()})
+}
+
+// load 返回最近发布的时间，不需要加锁
+func (s *Simulator) load() snapshot {
+	return s.snap.Load().(snapshot)
+}
+
+// register 把新建的任务放入一个 shard，不需要加 s.mu
+func (s *Simulator) register(t *task) {
+	i := atomic.AddUint32(&s.nextShard, 1) % uint32(len(s.shards))
+	sh := &s.shards[i]
+	t.index = registered
+	sh.mu.Lock()
+	sh.tasks = append(sh.tasks, t)
+	sh.mu.Unlock()
+	atomic.AddInt32(&s.registered, 1)
+	s.notifyAccepted()
+	s.poke()
+}
+
+// drain 把 shards 中的任务移入 heap
+// NOTICE: 务必在临界区内运行此方法
+func (s *Simulator) drain() {
+	if atomic.LoadInt32(&s.registered) == 0 {
+		return
+	}
+	for i := range s.shards {
+		sh := &s.shards[i]
+		sh.mu.Lock()
+		tasks := sh.tasks
+		sh.tasks = nil
+		sh.mu.Unlock()
+		for _, t := range tasks {
+			s.heap.push(t)
+		}
+		atomic.AddInt32(&s.registered, -int32(len(tasks)))
+	}
+}
+
+// notifyAccepted 唤醒正在 BlockUntil 中等待的 goroutine
+func (s *Simulator) notifyAccepted() {
+	// 没有 goroutine 在等待时，不用去争抢 acceptMu
+	if atomic.LoadInt32(&s.waiting) == 0 {
+		return
+	}
+	s.acceptMu.Lock()
+	defer s.acceptMu.Unlock()
+	if s.accepted != nil {
+		close(s.accepted)
+		s.accepted = nil
+		atomic.StoreInt32(&s.waiting, 0)
+	}
+}
+
+// poke 通知 s.wake 有新的任务放入，不会阻塞
+func (s *Simulator) poke() {
+	if s.wake == nil {
+		return
+	}
+	select {
+	case s.wake <- struct{}{}:
+	default:
+	}
+}
+
+// acceptedChan 返回下一次有任务放入时，会被关闭的 channel
+// NOTICE: 务必在检查任务数之前调用，notifyAccepted 才不会错过这一次等待
+func (s *Simulator) acceptedChan() <-chan struct{} {
+	s.acceptMu.Lock()
+	defer s.acceptMu.Unlock()
+	if s.accepted == nil {
+		s.accepted = make(chan struct{})
+		atomic.StoreInt32(&s.waiting, 1)
+	}
+	return s.accepted
+}
@@ -0,0 +1,103 @@
+package clock
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func Test_Simulator_register(t *testing.T) {
+	Convey("并发地新建 timer", t, func() {
+		now := time.Date(2020, 5, 20, 0, 0, 0, 0, time.UTC)
+		s := NewSimulator(now)
+		const n = 100
+		var wg sync.WaitGroup
+		var fired int32
+		for i := 0; i < n; i++ {
+			wg.Add(1)
+			go func(i int) {
+				defer wg.Done()
+				s.AfterFunc(time.Duration(i%10+1)*time.Second, func() {
+					atomic.AddInt32(&fired, 1)
+				})
+			}(i)
+		}
+		wg.Wait()
+		Convey("新建的 timer 都在等待触发", func() {
+			So(len(s.Pending()), ShouldEqual, n)
+			So(s.BlockUntil(context.Background(), n), ShouldBeNil)
+		})
+		Convey("驱动以后，全部都会触发", func() {
+			s.SyncCallbacks(time.Second)
+			s.Add(10 * time.Second)
+			So(atomic.LoadInt32(&fired), ShouldEqual, n)
+			So(len(s.Pending()), ShouldEqual, 0)
+		})
+		Convey("还没有放入 heap 的 timer 也可以 Stop 和 Reset", func() {
+			timer := s.NewTimer(time.Second)
+			So(timer.task.hasStopped(), ShouldBeFalse)
+			So(timer.Stop(), ShouldBeTrue)
+			timer = s.NewTimer(time.Second)
+			So(timer.Reset(time.Minute), ShouldBeTrue)
+			So(s.Pending()[n], ShouldEqual, now.Add(time.Minute))
+		})
+	})
+}
+
+func Test_Simulator_snapshot(t *testing.T) {
+	Convey("不加锁读取的时间与 s.now 一致", t, func() {
+		now := time.Date(2020, 5, 20, 0, 0, 0, 0, time.UTC)
+		s := NewSimulator(now)
+		s.Add(time.Second)
+		So(s.Now(), ShouldEqual, now.Add(time.Second))
+		So(s.MonotonicNow(), ShouldEqual, now.Add(time.Second))
+		Convey("墙上时间跳变后，也会更新", func() {
+			s.StepWall(time.Hour)
+			So(s.Now(), ShouldEqual, now.Add(time.Hour+time.Second))
+			So(s.MonotonicNow(), ShouldEqual, now.Add(time.Second))
+		})
+	})
+}
+
+func benchmarkParallel(b *testing.B, f func(s *Simulator)) {
+	s := NewSimulator(time.Date(2020, 5, 20, 0, 0, 0, 0, time.UTC))
+	b.ReportAllocs()
+	b.ResetTimer()
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			f(s)
+		}
+	})
+}
+
+// 使用 go test -bench Simulator -cpu 1,2,4,8 查看不同 GOMAXPROCS 下的扩展性
+
+func Benchmark_Simulator_Now(b *testing.B) {
+	benchmarkParallel(b, func(s *Simulator) {
+		s.Now()
+	})
+}
+
+func Benchmark_Simulator_Since(b *testing.B) {
+	now := time.Date(2020, 5, 20, 0, 0, 0, 0, time.UTC)
+	benchmarkParallel(b, func(s *Simulator) {
+		s.Since(now)
+	})
+}
+
+func Benchmark_Simulator_NewTimer(b *testing.B) {
+	benchmarkParallel(b, func(s *Simulator) {
+		s.NewTimer(time.Second)
+	})
+}
+
+func Benchmark_Simulator_NewTimerWhileAdding(b *testing.B) {
+	benchmarkParallel(b, func(s *Simulator) {
+		s.AfterFunc(time.Millisecond, func() {})
+		s.Add(time.Millisecond)
+	})
+}
@@ -0,0 +1,259 @@
+// Package sim 是基于 *clock.Simulator 的离散事件仿真工具，风格与 SimPy 类似。
+//
+// Process 是运行在自己的 goroutine 中的参与者，
+// 通过 Wait，Request，Get，Put 等方法让出执行权，等待虚拟时间的流逝。
+// 同一时刻只有一个 Process 在运行，
+// 同一时刻被唤醒的 Process，按照事件产生的顺序依次运行，
+// 所以，同样的模型，每次仿真的结果都是一样的。
+//
+// 虚拟时间由 Simulator 的 heap 推动：Environment 会在 Simulator 中
+// 为下一个事件放置一个 timer，再调用 Simulator.Move 前进到那一时刻。
+// 所以，Simulator 中的其他 timer 也会按照时间顺序正常触发。
+//
+// NOTICE: Process 中只能通过 Process 的方法等待，
+// 直接使用 Simulator 的 Sleep 或 timer 会让整个仿真停下来。
+package sim
+
+import (
+	"container/heap"
+	"fmt"
+	"time"
+
+	"github.com/jujili/clock"
+)
+
+// Environment 管理所有的 Process 和事件
+//
+// NOTICE: Environment 不是并发安全的，
+// 只能在 Run 所在的 goroutine 和 Process 中使用。
+type Environment struct {
+	s      *clock.Simulator
+	events eventHeap
+	seq    uint64
+	// ready 是可以在当前时刻运行的 Process，先进先出
+	ready []*Process
+	// yield 用于正在运行的 Process 把执行权交还给 Run
+	yield chan struct{}
+	// alarm 是放在 Simulator 中的 timer，在下一个事件的时刻触发
+	alarm   *clock.Timer
+	alarmAt time.Time
+	// panicked 保存 Process 中的 panic，由 Run 重新抛出
+	panicked interface{}
+}
+
+// NewEnvironment 返回在 s 上运行的 *Environment
+func NewEnvironment(s *clock.Simulator) *Environment {
+	return &Environment{
+		s:     s,
+		yield: make(chan struct{}),
+	}
+}
+
+// Simulator 返回 e 所使用的 *clock.Simulator
+func (e *Environment) Simulator() *clock.Simulator {
+	return e.s
+}
+
+// Now 返回当前的虚拟时间
+func (e *Environment) Now() time.Time {
+	return e.s.Now()
+}
+
+// Process 创建一个名为 name，运行 f 的 Process。
+// Process 会在当前时刻，等已经可以运行的 Process 都让出执行权以后开始运行。
+func (e *Environment) Process(name string, f func(p *Process)) *Process {
+	p := &Process{
+		env:    e,
+		name:   name,
+		resume: make(chan struct{}),
+	}
+	go func() {
+		<-p.resume
+		defer func() {
+			if r := recover(); r != nil {
+				e.panicked = fmt.Sprintf("sim: process %s panicked: %v", p.name, r)
+			}
+			p.done = true
+			for _, j := range p.joiners {
+				e.wake(j)
+			}
+			p.joiners = nil
+			e.yield <- struct{}{}
+		}()
+		f(p)
+	}()
+	e.wake(p)
+	return p
+}
+
+// Run 一直运行到没有任何事件为止
+func (e *Environment) Run() {
+	e.run(time.Time{}, false)
+}
+
+// RunUntil 运行到 until 时刻为止，until 时刻的事件也会被处理。
+// 返回时，Simulator 的时间是 until。
+func (e *Environment) RunUntil(until time.Time) {
+	e.run(until, true)
+}
+
+// RunFor 运行 d 时长
+func (e *Environment) RunFor(d time.Duration) {
+	e.RunUntil(e.Now().Add(d))
+}
+
+func (e *Environment) run(until time.Time, limited bool) {
+	for {
+		e.runReady()
+		if len(e.events) == 0 {
+			break
+		}
+		next := e.events[0].at
+		if limited && next.After(until) {
+			break
+		}
+		// alarm 在 next 时刻，所以不会越过 next
+		for e.s.Now().Before(next) {
+			e.s.Move()
+		}
+		now := e.s.Now()
+		for len(e.events) > 0 && !e.events[0].at.After(now) {
+			ev := heap.Pop(&e.events).(*event)
+			ev.fn()
+		}
+		e.arm()
+	}
+	if !limited {
+		return
+	}
+	if d := e.s.Until(until); d > 0 {
+		e.s.Add(d)
+	}
+}
+
+// runReady 依次运行所有可以运行的 Process，直到它们都让出执行权
+func (e *Environment) runReady() {
+	for len(e.ready) > 0 {
+		p := e.ready[0]
+		e.ready = e.ready[1:]
+		p.resume <- struct{}{}
+		<-e.yield
+		if e.panicked != nil {
+			panic(e.panicked)
+		}
+	}
+}
+
+// schedule 在 at 时刻运行 fn
+func (e *Environment) schedule(at time.Time, fn func()) {
+	e.seq++
+	heap.Push(&e.events, &event{at: at, seq: e.seq, fn: fn})
+	e.arm()
+}
+
+// wake 让 p 在当前时刻继续运行
+func (e *Environment) wake(p *Process) {
+	e.ready = append(e.ready, p)
+}
+
+// arm 让 alarm 在下一个事件的时刻触发
+func (e *Environment) arm() {
+	if len(e.events) == 0 {
+		return
+	}
+	next := e.events[0].at
+	if e.alarm != nil && e.alarmAt.Equal(next) {
+		return
+	}
+	if e.alarm != nil {
+		e.alarm.Stop()
+	}
+	e.alarmAt = next
+	// alarm 只是为了让 Simulator.Move 停在 next，回调什么都不用做
+	e.alarm = e.s.AfterFunc(e.s.Until(next), func() {})
+}
+
+type event struct {
+	at  time.Time
+	seq uint64
+	fn  func()
+}
+
+// eventHeap 按照时间排列，同一时间的按照产生的顺序排列
+type eventHeap []*event
+
+func (h eventHeap) Len() int { return len(h) }
+
+func (h eventHeap) Less(i, j int) bool {
+	if !h[i].at.Equal(h[j].at) {
+		return h[i].at.Before(h[j].at)
+	}
+	return h[i].seq < h[j].seq
+}
+
+func (h eventHeap) Swap(i, j int) { h[i], h[j] = h[j], h[i] }
+
+func (h *eventHeap) Push(x interface{}) { *h = append(*h, x.(*event)) }
+
+func (h *eventHeap) Pop() interface{} {
+	old := *h
+	n := len(old)
+	x := old[n-1]
+	*h = old[:n-1]
+	return x
+}
+
+// Process 是仿真中的一个参与者
+type Process struct {
+	env     *Environment
+	name    string
+	resume  chan struct{}
+	done    bool
+	joiners []*Process
+}
+
+// Name 返回 Process 的名称
+func (p *Process) Name() string {
+	return p.name
+}
+
+// Env 返回 p 所在的 *Environment
+func (p *Process) Env() *Environment {
+	return p.env
+}
+
+// Now 返回当前的虚拟时间
+func (p *Process) Now() time.Time {
+	return p.env.Now()
+}
+
+// Done 报告 p 是否已经运行完毕
+func (p *Process) Done() bool {
+	return p.done
+}
+
+// block 把执行权交还给 Environment，直到 p 被唤醒
+// NOTICE: 调用前，务必安排好唤醒 p 的事件
+func (p *Process) block() {
+	p.env.yield <- struct{}{}
+	<-p.resume
+}
+
+// Wait 等待 d 时长的虚拟时间。
+// d <= 0 时，也会让出执行权，让同一时刻的其他 Process 先运行。
+func (p *Process) Wait(d time.Duration) {
+	if d < 0 {
+		d = 0
+	}
+	p.env.schedule(p.Now().Add(d), func() { p.env.wake(p) })
+	p.block()
+}
+
+// Join 等待 q 运行完毕
+func (p *Process) Join(q *Process) {
+	if q.done {
+		return
+	}
+	q.joiners = append(q.joiners, p)
+	p.block()
+}
@@ -0,0 +1,111 @@
+package sim
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/jujili/clock"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+var start = time.Date(2020, 5, 20, 0, 0, 0, 0, time.UTC)
+
+func Test_Environment(t *testing.T) {
+	Convey("在 Environment 中运行 Process", t, func() {
+		s := clock.NewSimulator(start)
+		env := NewEnvironment(s)
+		So(env.Simulator(), ShouldEqual, s)
+		var log []string
+		record := func(p *Process, what string) {
+			log = append(log, fmt.Sprintf("%s %s %s", p.Now().Sub(start), p.Name(), what))
+		}
+		Convey("Process 按照虚拟时间交替运行", func() {
+			for i, name := range []string{"a", "b"} {
+				d := time.Duration(i+2) * time.Second
+				env.Process(name, func(p *Process) {
+					for j := 0; j < 2; j++ {
+						p.Wait(d)
+						record(p, "tick")
+					}
+				})
+			}
+			env.Run()
+			So(log, ShouldResemble, []string{
+				"2s a tick",
+				"3s b tick",
+				"4s a tick",
+				"6s b tick",
+			})
+			So(env.Now(), ShouldEqual, start.Add(6*time.Second))
+		})
+		Convey("同一时刻的 Process 按照事件产生的顺序运行", func() {
+			for _, name := range []string{"a", "b", "c"} {
+				env.Process(name, func(p *Process) {
+					p.Wait(time.Second)
+					record(p, "wake")
+					p.Wait(0)
+					record(p, "again")
+				})
+			}
+			env.Run()
+			So(log, ShouldResemble, []string{
+				"1s a wake", "1s b wake", "1s c wake",
+				"1s a again", "1s b again", "1s c again",
+			})
+		})
+		Convey("Process 可以创建并等待其他 Process", func() {
+			var child *Process
+			env.Process("parent", func(p *Process) {
+				child = env.Process("child", func(c *Process) {
+					c.Wait(5 * time.Second)
+					record(c, "done")
+				})
+				p.Join(child)
+				record(p, "joined")
+				p.Join(child)
+				record(p, "joined again")
+			})
+			env.Run()
+			So(log, ShouldResemble, []string{
+				"5s child done",
+				"5s parent joined",
+				"5s parent joined again",
+			})
+			So(child.Done(), ShouldBeTrue)
+		})
+		Convey("RunUntil 在指定的时刻停下来，之后可以继续运行", func() {
+			env.Process("a", func(p *Process) {
+				for {
+					p.Wait(time.Second)
+					record(p, "tick")
+				}
+			})
+			env.RunUntil(start.Add(2500 * time.Millisecond))
+			So(len(log), ShouldEqual, 2)
+			So(env.Now(), ShouldEqual, start.Add(2500*time.Millisecond))
+			env.RunFor(500 * time.Millisecond)
+			So(len(log), ShouldEqual, 3)
+			So(env.Now(), ShouldEqual, start.Add(3*time.Second))
+		})
+		Convey("Simulator 中其他的 timer 按照时间顺序触发", func() {
+			fired := s.NewTimer(1500 * time.Millisecond).C
+			env.Process("a", func(p *Process) {
+				p.Wait(time.Second)
+				p.Wait(time.Second)
+			})
+			env.RunUntil(start.Add(time.Second))
+			So(len(fired), ShouldEqual, 0)
+			env.Run()
+			So(<-fired, ShouldEqual, start.Add(1500*time.Millisecond))
+			So(env.Now(), ShouldEqual, start.Add(2*time.Second))
+		})
+		Convey("Process 中的 panic 由 Run 抛出", func() {
+			env.Process("bad", func(p *Process) {
+				p.Wait(time.Second)
+				panic("boom")
+			})
+			So(env.Run, ShouldPanicWith, "sim: process bad panicked: boom")
+		})
+	})
+}
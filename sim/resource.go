@@ -0,0 +1,99 @@
+package sim
+
+import "time"
+
+// Resource 是容量有限的资源，例如：服务器的工作线程。
+// 资源不足时，Request 的 Process 按照先来后到的顺序排队。
+type Resource struct {
+	env      *Environment
+	capacity int
+	inUse    int
+	queue    []*request
+	waits    Tally
+	queueLen *TimeWeighted
+	usage    *TimeWeighted
+}
+
+type request struct {
+	p  *Process
+	at time.Time
+}
+
+// NewResource 返回容量为 capacity 的 *Resource
+// capacity 必须大于 0
+func (e *Environment) NewResource(capacity int) *Resource {
+	if capacity <= 0 {
+		panic("sim: resource capacity must be positive")
+	}
+	return &Resource{
+		env:      e,
+		capacity: capacity,
+		queueLen: NewTimeWeighted(e.s, 0),
+		usage:    NewTimeWeighted(e.s, 0),
+	}
+}
+
+// Capacity 返回 r 的容量
+func (r *Resource) Capacity() int {
+	return r.capacity
+}
+
+// InUse 返回正在被使用的数量
+func (r *Resource) InUse() int {
+	return r.inUse
+}
+
+// Queued 返回正在排队的 Process 的数量
+func (r *Resource) Queued() int {
+	return len(r.queue)
+}
+
+// WaitTimes 返回每次 Request 的排队时间，单位是秒
+func (r *Resource) WaitTimes() *Tally {
+	return &r.waits
+}
+
+// QueueLength 返回队列长度随时间的变化
+func (r *Resource) QueueLength() *TimeWeighted {
+	return r.queueLen
+}
+
+// Usage 返回使用数量随时间的变化，Usage().Mean() / Capacity() 就是利用率
+func (r *Resource) Usage() *TimeWeighted {
+	return r.usage
+}
+
+// Request 占用 r 的一个单位，r 没有空闲时，排队等待
+func (p *Process) Request(r *Resource) {
+	if r.inUse < r.capacity && len(r.queue) == 0 {
+		r.grant(0)
+		return
+	}
+	r.queue = append(r.queue, &request{p: p, at: p.Now()})
+	r.queueLen.Set(float64(len(r.queue)))
+	p.block()
+}
+
+// Release 归还 p 占用的 r 的一个单位，
+// 排在队首的 Process 会在当前时刻得到这个单位。
+func (p *Process) Release(r *Resource) {
+	if r.inUse == 0 {
+		panic("sim: release of unused resource")
+	}
+	r.inUse--
+	r.usage.Set(float64(r.inUse))
+	if len(r.queue) == 0 {
+		return
+	}
+	req := r.queue[0]
+	r.queue = r.queue[1:]
+	r.queueLen.Set(float64(len(r.queue)))
+	r.grant(p.Now().Sub(req.at))
+	r.env.wake(req.p)
+}
+
+func (r *Resource) grant(waited time.Duration) {
+	r.inUse++
+	r.usage.Set(float64(r.inUse))
+	r.waits.ObserveDuration(waited)
+}
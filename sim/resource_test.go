@@ -0,0 +1,56 @@
+package sim
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/jujili/clock"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func Test_Resource(t *testing.T) {
+	Convey("容量为 1 的 Resource", t, func() {
+		s := clock.NewSimulator(start)
+		env := NewEnvironment(s)
+		r := env.NewResource(1)
+		So(r.Capacity(), ShouldEqual, 1)
+		var log []string
+		Convey("Process 按照先来后到的顺序使用", func() {
+			for i := 0; i < 3; i++ {
+				name := fmt.Sprint("c", i)
+				env.Process(name, func(p *Process) {
+					p.Request(r)
+					log = append(log, fmt.Sprintf("%s %s", p.Now().Sub(start), p.Name()))
+					p.Wait(2 * time.Second)
+					p.Release(r)
+				})
+			}
+			env.RunUntil(start.Add(time.Second))
+			So(r.InUse(), ShouldEqual, 1)
+			So(r.Queued(), ShouldEqual, 2)
+			env.Run()
+			So(log, ShouldResemble, []string{"0s c0", "2s c1", "4s c2"})
+			So(r.InUse(), ShouldEqual, 0)
+			Convey("记录排队的时间和队列的长度", func() {
+				w := r.WaitTimes()
+				So(w.Count(), ShouldEqual, 3)
+				So(w.Mean(), ShouldEqual, 2)
+				So(w.Max(), ShouldEqual, 4)
+				// 队列长度：[0,2) 为 2，[2,4) 为 1，[4,6) 为 0
+				So(r.QueueLength().Mean(), ShouldEqual, 1)
+				So(r.QueueLength().Max(), ShouldEqual, 2)
+				So(r.Usage().Mean(), ShouldEqual, 1)
+			})
+		})
+		Convey("归还没有使用的 Resource 会 panic", func() {
+			env.Process("bad", func(p *Process) {
+				p.Release(r)
+			})
+			So(env.Run, ShouldPanic)
+		})
+		Convey("容量必须大于 0", func() {
+			So(func() { env.NewResource(0) }, ShouldPanicWith, "sim: resource capacity must be positive")
+		})
+	})
+}
@@ -0,0 +1,141 @@
+package sim
+
+import (
+	"math"
+	"time"
+
+	"github.com/jujili/clock"
+)
+
+// Tally 统计一组观测值，例如：每个请求的等待时间
+type Tally struct {
+	n        int
+	mean, m2 float64
+	min, max float64
+}
+
+// Observe 记录一个观测值
+func (t *Tally) Observe(x float64) {
+	t.n++
+	if t.n == 1 || x < t.min {
+		t.min = x
+	}
+	if t.n == 1 || x > t.max {
+		t.max = x
+	}
+	// Welford 算法，避免累加平方带来的误差
+	delta := x - t.mean
+	t.mean += delta / float64(t.n)
+	t.m2 += delta * (x - t.mean)
+}
+
+// ObserveDuration 以秒为单位记录 d
+func (t *Tally) ObserveDuration(d time.Duration) {
+	t.Observe(d.Seconds())
+}
+
+// Count 返回观测值的个数
+func (t *Tally) Count() int {
+	return t.n
+}
+
+// Mean 返回平均值，没有观测值时返回 0
+func (t *Tally) Mean() float64 {
+	return t.mean
+}
+
+// Min 返回最小值，没有观测值时返回 0
+func (t *Tally) Min() float64 {
+	return t.min
+}
+
+// Max 返回最大值，没有观测值时返回 0
+func (t *Tally) Max() float64 {
+	return t.max
+}
+
+// Variance 返回样本方差，观测值少于两个时返回 0
+func (t *Tally) Variance() float64 {
+	if t.n < 2 {
+		return 0
+	}
+	return t.m2 / float64(t.n-1)
+}
+
+// StdDev 返回样本标准差
+func (t *Tally) StdDev() float64 {
+	return math.Sqrt(t.Variance())
+}
+
+// TimeWeighted 统计随时间变化的量，例如：队列的长度，
+// 每个值按照它持续的时间加权。
+type TimeWeighted struct {
+	c        clock.Clock
+	start    time.Time
+	last     time.Time
+	value    float64
+	area     float64
+	min, max float64
+}
+
+// NewTimeWeighted 返回从 c.Now() 开始，初始值为 v 的 *TimeWeighted
+func NewTimeWeighted(c clock.Clock, v float64) *TimeWeighted {
+	now := c.Now()
+	return &TimeWeighted{
+		c:     c,
+		start: now,
+		last:  now,
+		value: v,
+		min:   v,
+		max:   v,
+	}
+}
+
+// update 把上一个值持续的时间累加到面积中
+func (w *TimeWeighted) update() {
+	now := w.c.Now()
+	w.area += w.value * now.Sub(w.last).Seconds()
+	w.last = now
+}
+
+// Set 把当前值改为 v
+func (w *TimeWeighted) Set(v float64) {
+	w.update()
+	w.value = v
+	if v < w.min {
+		w.min = v
+	}
+	if v > w.max {
+		w.max = v
+	}
+}
+
+// Add 把当前值增加 delta
+func (w *TimeWeighted) Add(delta float64) {
+	w.Set(w.value + delta)
+}
+
+// Value 返回当前值
+func (w *TimeWeighted) Value() float64 {
+	return w.value
+}
+
+// Mean 返回从开始到现在的时间加权平均值，还没有经过任何时间时，返回当前值
+func (w *TimeWeighted) Mean() float64 {
+	w.update()
+	elapsed := w.last.Sub(w.start).Seconds()
+	if elapsed <= 0 {
+		return w.value
+	}
+	return w.area / elapsed
+}
+
+// Min 返回出现过的最小值
+func (w *TimeWeighted) Min() float64 {
+	return w.min
+}
+
+// Max 返回出现过的最大值
+func (w *TimeWeighted) Max() float64 {
+	return w.max
+}
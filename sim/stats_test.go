@@ -0,0 +1,49 @@
+package sim
+
+import (
+	"testing"
+	"time"
+
+	"github.com/jujili/clock"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func Test_Tally(t *testing.T) {
+	Convey("Tally 统计观测值", t, func() {
+		var tl Tally
+		So(tl.Count(), ShouldEqual, 0)
+		So(tl.Mean(), ShouldEqual, 0)
+		So(tl.Variance(), ShouldEqual, 0)
+		for _, x := range []float64{2, 4, 4, 4, 5, 5, 7, 9} {
+			tl.Observe(x)
+		}
+		So(tl.Count(), ShouldEqual, 8)
+		So(tl.Mean(), ShouldEqual, 5)
+		So(tl.Min(), ShouldEqual, 2)
+		So(tl.Max(), ShouldEqual, 9)
+		So(tl.Variance(), ShouldAlmostEqual, 32.0/7)
+		So(tl.StdDev()*tl.StdDev(), ShouldAlmostEqual, 32.0/7)
+		Convey("时长以秒为单位", func() {
+			tl.ObserveDuration(10 * time.Second)
+			So(tl.Max(), ShouldEqual, 10)
+		})
+	})
+}
+
+func Test_TimeWeighted(t *testing.T) {
+	Convey("TimeWeighted 按照持续时间加权", t, func() {
+		s := clock.NewSimulator(start)
+		w := NewTimeWeighted(s, 1)
+		So(w.Mean(), ShouldEqual, 1)
+		s.Add(time.Second)
+		w.Set(4)
+		s.Add(2 * time.Second)
+		w.Add(-4)
+		s.Add(time.Second)
+		// (1*1 + 4*2 + 0*1) / 4
+		So(w.Mean(), ShouldEqual, 2.25)
+		So(w.Value(), ShouldEqual, 0)
+		So(w.Min(), ShouldEqual, 0)
+		So(w.Max(), ShouldEqual, 4)
+	})
+}
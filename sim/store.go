@@ -0,0 +1,82 @@
+package sim
+
+// Store 是先进先出的物品队列，例如：消息队列。
+// Store 为空时，Get 的 Process 排队等待；
+// Store 已满时，Put 的 Process 排队等待。
+type Store struct {
+	env      *Environment
+	capacity int
+	items    []interface{}
+	getters  []*waiter
+	putters  []*waiter
+	level    *TimeWeighted
+}
+
+// waiter 是排队的 Process，以及它要放入或者取到的物品
+type waiter struct {
+	p    *Process
+	item interface{}
+}
+
+// NewStore 返回容量为 capacity 的 *Store
+// capacity <= 0 表示没有容量限制
+func (e *Environment) NewStore(capacity int) *Store {
+	return &Store{
+		env:      e,
+		capacity: capacity,
+		level:    NewTimeWeighted(e.s, 0),
+	}
+}
+
+// Len 返回 st 中物品的数量
+func (st *Store) Len() int {
+	return len(st.items)
+}
+
+// Level 返回物品数量随时间的变化
+func (st *Store) Level() *TimeWeighted {
+	return st.level
+}
+
+func (st *Store) full() bool {
+	return st.capacity > 0 && len(st.items) >= st.capacity
+}
+
+// Put 把 item 放入 st，st 已满时，排队等待
+func (p *Process) Put(st *Store, item interface{}) {
+	if len(st.getters) > 0 {
+		// 有 Process 在等待时，物品直接交给排在队首的那个
+		g := st.getters[0]
+		st.getters = st.getters[1:]
+		g.item = item
+		st.env.wake(g.p)
+		return
+	}
+	if !st.full() {
+		st.items = append(st.items, item)
+		st.level.Set(float64(len(st.items)))
+		return
+	}
+	st.putters = append(st.putters, &waiter{p: p, item: item})
+	p.block()
+}
+
+// Get 从 st 中取出最早放入的物品，st 为空时，排队等待
+func (p *Process) Get(st *Store) interface{} {
+	if len(st.items) > 0 {
+		item := st.items[0]
+		st.items = st.items[1:]
+		if len(st.putters) > 0 {
+			pt := st.putters[0]
+			st.putters = st.putters[1:]
+			st.items = append(st.items, pt.item)
+			st.env.wake(pt.p)
+		}
+		st.level.Set(float64(len(st.items)))
+		return item
+	}
+	g := &waiter{p: p}
+	st.getters = append(st.getters, g)
+	p.block()
+	return g.item
+}
@@ -0,0 +1,60 @@
+package sim
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/jujili/clock"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func Test_Store(t *testing.T) {
+	Convey("生产者和消费者", t, func() {
+		s := clock.NewSimulator(start)
+		env := NewEnvironment(s)
+		var log []string
+		record := func(p *Process, what interface{}) {
+			log = append(log, fmt.Sprintf("%s %s %v", p.Now().Sub(start), p.Name(), what))
+		}
+		Convey("Store 为空时，Get 等待", func() {
+			st := env.NewStore(0)
+			env.Process("consumer", func(p *Process) {
+				for i := 0; i < 2; i++ {
+					record(p, p.Get(st))
+				}
+			})
+			env.Process("producer", func(p *Process) {
+				for i := 0; i < 2; i++ {
+					p.Wait(time.Second)
+					p.Put(st, i)
+				}
+			})
+			env.Run()
+			So(log, ShouldResemble, []string{"1s consumer 0", "2s consumer 1"})
+			So(st.Len(), ShouldEqual, 0)
+		})
+		Convey("Store 已满时，Put 等待", func() {
+			st := env.NewStore(1)
+			env.Process("producer", func(p *Process) {
+				for i := 0; i < 3; i++ {
+					p.Put(st, i)
+					record(p, i)
+				}
+			})
+			env.Process("consumer", func(p *Process) {
+				for i := 0; i < 3; i++ {
+					p.Wait(time.Second)
+					p.Get(st)
+				}
+			})
+			env.RunUntil(start.Add(500 * time.Millisecond))
+			So(st.Len(), ShouldEqual, 1)
+			env.Run()
+			So(log, ShouldResemble, []string{"0s producer 0", "1s producer 1", "2s producer 2"})
+			So(st.Len(), ShouldEqual, 0)
+			So(st.Level().Max(), ShouldEqual, 1)
+			So(st.Level().Mean(), ShouldEqual, 1)
+		})
+	})
+}
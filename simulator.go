@@ -3,7 +3,9 @@ package clock
 import (
 	"context"
 	"runtime"
+	"sort"
 	"sync"
+	"sync/atomic"
 	"time"
 )
 
@@ -15,29 +17,66 @@ const (
 //
 // 为了尽可能真实地模拟时间的流逝，Simulator.now 只会不断变大，不会出现逆转情况。
 //
-// RWMutex 锁住 Simulator 时，其他 goroutine 的 Simulator 方法会被阻塞。
+// 驱动 Simulator 时，其他 goroutine 中访问 heap 的 Simulator 方法会被阻塞，锁的设计详见 shard.go。
 // Simulator 的运行也不适均匀的，有可能下一个时刻就是很久以后。
 // 这是与 time 标准库的主要差异，使用 Simulator 时，请特别注意。
 //
+// Simulator 产生的所有时间都不带有单调时钟读数，
+// 所以，其 Sub，Equal 和 == 的行为都只取决于墙上时间和时区。
+// 比较时间是否相等时，与使用 time 标准库时一样，请使用 Equal 而不是 ==。
+// Since 和 Until 则模拟了带有单调时钟读数时的行为。
+//
+// Now 返回的是墙上时间，可以使用 StepWall 和 SlewWall 调整，
+// .Add*，.Set* 和 .Move 驱动的是单调时间，详见 wall.go
 type Simulator struct {
-	sync.RWMutex
+	// syncTimeout > 0 表示打开了同步回调模式，详见 callback.go
+	// 新建 AfterFunc 时不加锁读取，所以使用 atomic，
+	// 放在第一个，保证在 32 位平台上也是 64 位对齐的
+	syncTimeout int64
+
+	mu   sync.RWMutex
 	now  time.Time
 	heap *taskHeap
+	// snap 是最近发布的 snapshot，用于不加锁地读取当前时间
+	snap atomic.Value
+	// shards 暂存新建的任务，registered 是暂存的任务数，详见 shard.go
+	shards     []shard
+	nextShard  uint32
+	registered int32
+	// wall 描述了墙上时间相对于单调时间的偏移，至少有一段
+	wall []wallSegment
+	// accepted 会在有新的任务放入时被关闭，用于唤醒 BlockUntil，由 acceptMu 保护
+	// waiting 为 1 表示有 goroutine 在等待 accepted
+	acceptMu sync.Mutex
+	accepted chan struct{}
+	waiting  int32
+	// actors 记录了每个 Actor 花费的虚拟时间，详见 actor.go
+	actors map[string]*ActorStats
+	// step 是正在触发任务的那一次 Add，Set，Move 或 Run* 的回调记录
+	step *callbackStep
+	// watchdog 是触发单个任务的最长时间（真实时间），详见 watchdog.go
+	watchdog time.Duration
+	// wake 不是 nil 的话，有新的任务放入时，会收到通知，详见 pausable.go
+	// 只在 NewPausableClock 中设置一次
+	wake chan struct{}
 }
 
 // NewSimulator 返回一个以 now 为当前时间的虚拟时钟。
+// now 中的单调时钟读数会被去除。
 func NewSimulator(now time.Time) *Simulator {
-	return &Simulator{
-		now:  now,
-		heap: newTaskHeap(),
+	s := &Simulator{
+		now:    now.Round(0),
+		heap:   newTaskHeap(),
+		shards: newShards(),
+		wall:   []wallSegment{{}},
 	}
+	s.publish()
+	return s
 }
 
-// Now returns the current time.
+// Now returns the current wall time.
 func (s *Simulator) Now() time.Time {
-	s.RLock()
-	defer s.RUnlock()
-	return s.now
+	return s.load().wall
 }
 
 // Add advances the current time by duration d and fires all expired timers if d >= 0,
@@ -46,108 +85,135 @@ func (s *Simulator) Now() time.Time {
 // Returns the current time.
 // 推荐使用 AddOrPanic 替换此方法
 func (s *Simulator) Add(d time.Duration) time.Time {
-	s.Lock()
-	defer s.Unlock()
+	s.mu.Lock()
+	st := s.newStep()
+	defer st.wait()
+	defer s.mu.Unlock()
 	if d < 0 {
-		return s.now
+		return s.wallNow()
 	}
-	now, _ := s.set(s.now.Add(d))
-	return now
+	s.set(s.now.Add(d), st)
+	return s.wallNow()
 }
 
 // AddOrPanic advances the current time by duration d and fires all expired timers if d >= 0
 // else panic
 // Returns the new current time.
 func (s *Simulator) AddOrPanic(d time.Duration) time.Time {
-	s.Lock()
-	defer s.Unlock()
+	s.mu.Lock()
+	st := s.newStep()
+	defer st.wait()
+	defer s.mu.Unlock()
 	if d < 0 {
 		panic(timeReversal)
 	}
-	now, _ := s.set(s.now.Add(d))
-	return now
+	s.set(s.now.Add(d), st)
+	return s.wallNow()
 }
 
 // Move advances the current time to the next available timer deadline
 // Returns the new current time and the advanced duration.
 func (s *Simulator) Move() (time.Time, time.Duration) {
-	s.Lock()
-	defer s.Unlock()
+	s.mu.Lock()
+	st := s.newStep()
+	defer st.wait()
+	defer s.mu.Unlock()
 	last := s.now
+	s.drain()
 	if s.heap.hasTask() {
-		s.accomplishNextTask()
+		s.accomplishNextTask(st)
 	}
-	return s.now, s.now.Sub(last)
+	return s.wallNow(), s.now.Sub(last)
 }
 
-// Set advances the current time to t and fires all expired timers if s.now <= t
+// Set advances the current monotonic time to t and fires all expired timers if s.now <= t
 // else DO NOTHING
 // Returns the advanced duration.
 // NOTICE: 返回 0 还有可能是 t < s.now，不仅仅是 t = s.now
 // 推荐使用 SetOrPanic 替代此方法
 func (s *Simulator) Set(t time.Time) time.Duration {
-	s.Lock()
-	defer s.Unlock()
+	s.mu.Lock()
+	st := s.newStep()
+	defer st.wait()
+	defer s.mu.Unlock()
 	if t.Before(s.now) {
 		return 0
 	}
-	_, d := s.set(t)
+	_, d := s.set(t, st)
 	return d
 }
 
-// SetOrPanic advances the current time to t and fires all expired timers if s.now <= t
+// SetOrPanic advances the current monotonic time to t and fires all expired timers if s.now <= t
 // else panic with time reversal
 // Returns the advanced duration.
 func (s *Simulator) SetOrPanic(t time.Time) time.Duration {
-	s.Lock()
-	defer s.Unlock()
+	s.mu.Lock()
+	st := s.newStep()
+	defer st.wait()
+	defer s.mu.Unlock()
 	if t.Before(s.now) {
 		panic(timeReversal)
 	}
-	_, d := s.set(t)
+	_, d := s.set(t, st)
 	return d
 }
 
 // Since returns the time elapsed since t.
+// 与带有单调时钟读数的 time.Since 一样，不受墙上时间跳变的影响。
 func (s *Simulator) Since(t time.Time) time.Duration {
-	s.Lock()
-	defer s.Unlock()
-	return s.now.Sub(t)
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.now.Sub(s.monoOf(t))
 }
 
 // Until returns the duration until t.
+// 与带有单调时钟读数的 time.Until 一样，不受墙上时间跳变的影响。
 func (s *Simulator) Until(t time.Time) time.Duration {
-	s.Lock()
-	defer s.Unlock()
-	return t.Sub(s.now)
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.monoOf(t).Sub(s.now)
 }
 
 // ContextWithDeadline implements Clock.
 // NOTICE: 在程序中，不要混用 realClock 和 simulator
 func (s *Simulator) ContextWithDeadline(parent context.Context, deadline time.Time) (context.Context, context.CancelFunc) {
-	s.Lock()
-	defer s.Unlock()
+	s.mu.Lock()
+	defer s.mu.Unlock()
 	return s.contextWithDeadline(parent, deadline)
 }
 
 // ContextWithTimeout implements Clock.
 // NOTICE: 在程序中，不要混用 realClock 和 simulator
 func (s *Simulator) ContextWithTimeout(parent context.Context, timeout time.Duration) (context.Context, context.CancelFunc) {
-	s.Lock()
-	defer s.Unlock()
-	return s.contextWithDeadline(parent, s.now.Add(timeout))
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.localContextWithDeadline(s, parent, s.wallNow().Add(timeout), s.now.Add(timeout))
 }
 
 func (s *Simulator) contextWithDeadline(parent context.Context, deadline time.Time) (context.Context, context.CancelFunc) {
-	// 为 parent 注入 Simulator
-	child, cancel := context.WithCancel(Set(parent, s))
+	return s.localContextWithDeadline(s, parent, deadline, s.monoOf(deadline))
+}
+
+// localContextWithDeadline 为 parent 注入 c，
+// 返回的上下文的 Deadline 是 c 时间线上的 deadline，
+// 在 Simulator 时间线上的 fireAt 时刻到期。
+func (s *Simulator) localContextWithDeadline(c Clock, parent context.Context, deadline, fireAt time.Time) (context.Context, context.CancelFunc) {
+	child, cancel := context.WithCancel(Set(parent, c))
 	pd, ok := parent.Deadline()
 	pdEqualOrBeforeDeadline := !pd.After(deadline)
 	if ok && pdEqualOrBeforeDeadline {
 		return child, cancel
 	}
-	ctx := s.newContextSim(child, deadline)
-	return ctx, cancel
+	if !fireAt.After(s.now) {
+		// 与 context.WithDeadline 一样，已经过期的上下文，立即结束
+		return s.expiredContextSim(child, deadline), cancel
+	}
+	ctx := s.newLocalContextSim(child, deadline, fireAt)
+	// 与 context 标准库一样，cancel 返回后，ctx 已经结束
+	return ctx, func() {
+		cancel()
+		<-ctx.Done()
+	}
 }
 
 // set 是 Simulator 的核心逻辑，
@@ -159,10 +225,15 @@ func (s *Simulator) contextWithDeadline(parent context.Context, deadline time.Ti
 //
 // 当多个 set 同时调用时，会交替运行，并发安全。
 // 只是较小的输入参数 now，可能无法被赋值到 Simulator.now
-func (s *Simulator) set(now time.Time) (time.Time, time.Duration) {
+func (s *Simulator) set(now time.Time, st *callbackStep) (time.Time, time.Duration) {
 	last := s.now
-	for s.heap.hasExpiredTask(now) {
-		s.accomplishNextTask()
+	for {
+		// gosched 期间，其他 goroutine 可能新建了任务
+		s.drain()
+		if !s.heap.hasExpiredTask(now) {
+			break
+		}
+		s.accomplishNextTask(st)
 		s.gosched()
 	}
 	// 如果有多个 goroutine 在并行运行 set 的话。
@@ -181,29 +252,79 @@ func (s *Simulator) set(now time.Time) (time.Time, time.Duration) {
 // simulator 的运行情况更接近 real clock
 // 所以，才必须在临界区内执行
 func (s *Simulator) gosched() {
-	s.Unlock()
+	s.mu.Unlock()
 	runtime.Gosched()
-	s.Lock()
+	s.mu.Lock()
 }
 
-func (s *Simulator) accomplishNextTask() {
+// accomplishNextTask 触发下一个任务，AfterFunc 的回调记录在 st 中
+func (s *Simulator) accomplishNextTask(st *callbackStep) {
 	t := s.heap.pop()
 	// 因为有可能 task 在放入 heap 的时候，就已经过期了，
 	// 为了防止时间逆转
 	// 不能直接设置 s.now = t.deadline
 	s.setNowTo(t.deadline)
+	// t.run 的过程中不会离开临界区，所以 s.step 不会被其他的 goroutine 改变
+	s.step = st
+	stop := s.watch(t)
 	t = t.run()
+	stop()
+	s.step = nil
 	s.accept(t)
 }
 
 // accept 把 not nil 的任务放入自己的 heap。
 // 这里只需要检查 t 是否为 nil, 不会触发过期的 task。
 // 把触发工作全部丢给 s.accomplishNextTask 去完成。
+// 新建的任务请使用 s.register，不需要加锁。
+// NOTICE: 务必在临界区内运行此方法
 func (s *Simulator) accept(t *task) {
 	if t == nil {
 		return
 	}
 	s.heap.push(t)
+	s.notifyAccepted()
+	s.poke()
+}
+
+// Pending 返回所有等待触发的任务的到期时间（墙上时间），由早到晚排列。
+// 任务包括 Timer，Ticker，EveryDay 和上下文的到期时间。
+func (s *Simulator) Pending() []time.Time {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.drain()
+	h := *s.heap
+	res := make([]time.Time, 0, len(h))
+	for _, t := range h {
+		res = append(res, t.deadline)
+	}
+	sort.Slice(res, func(i, j int) bool { return res[i].Before(res[j]) })
+	for i := range res {
+		res[i] = s.wallAt(res[i])
+	}
+	return res
+}
+
+// BlockUntil 阻塞到 s 中至少有 n 个等待触发的任务为止。
+// 用于确保其他 goroutine 已经开始等待，再驱动 s。
+// ctx 先结束的话，返回 ctx.Err()
+func (s *Simulator) BlockUntil(ctx context.Context, n int) error {
+	for {
+		// 先取得 accepted，再检查任务数，免得错过检查之后放入的任务
+		accepted := s.acceptedChan()
+		s.mu.Lock()
+		s.drain()
+		count := s.heap.Len()
+		s.mu.Unlock()
+		if count >= n {
+			return nil
+		}
+		select {
+		case <-accepted:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
 }
 
 // setNowTo make m.now equal to t if m.now < t
@@ -213,6 +334,8 @@ func (s *Simulator) setNowTo(t time.Time) {
 		// Simulator 的所有方法中，
 		// 应该只有这一处存在 .now =
 		// 需要改变 s.now 的话，就调用此方法。
-		s.now = t
+		// t 有可能来自带有单调时钟读数的输入参数，需要去除
+		s.now = t.Round(0)
+		s.publish()
 	}
 }
@@ -196,7 +196,8 @@ func Test_Simulator_set_timerStyle(t *testing.T) {
 			deadline := now.Add(time.Duration(i) * time.Second)
 			ts := newTask(deadline, runTask)
 			s.accept(ts)
-			expectOrder[i-1] = deadline
+			// Simulator 中的时间不带有单调时钟读数
+			expectOrder[i-1] = deadline.Round(0)
 		}
 		Convey("s.heap 的长度应该等于 count", func() {
 			So(len(*(s.heap)), ShouldEqual, num)
@@ -204,9 +205,9 @@ func Test_Simulator_set_timerStyle(t *testing.T) {
 		Convey("改变 s 的当前时间", func() {
 			expectDur := time.Second * time.Duration(num)
 			expectTime := now.Add(expectDur)
-			s.Lock()
-			actualTime, actualDur := s.set(expectTime)
-			s.Unlock()
+			s.mu.Lock()
+			actualTime, actualDur := s.set(expectTime, nil)
+			s.mu.Unlock()
 			Convey("s 被改变，并按照预定的顺序执行", func() {
 				So(actualTime, ShouldEqual, expectTime)
 				So(actualDur, ShouldEqual, expectDur)
@@ -236,14 +237,15 @@ func Test_Simulator_set_tickerStyle(t *testing.T) {
 		expectOrder := make([]time.Time, num)
 		for i := 0; i < num; i++ {
 			deadline := now.Add(time.Duration(i+1) * time.Second)
-			expectOrder[i] = deadline
+			// Simulator 中的时间不带有单调时钟读数
+			expectOrder[i] = deadline.Round(0)
 		}
 		Convey("改变 s 的当前时间", func() {
 			expectDur := time.Second * time.Duration(num)
 			expectTime := now.Add(expectDur)
-			s.Lock()
-			actualTime, actualDur := s.set(expectTime)
-			s.Unlock()
+			s.mu.Lock()
+			actualTime, actualDur := s.set(expectTime, nil)
+			s.mu.Unlock()
 			Convey("s 被改变，并按照预定的顺序执行", func() {
 				So(actualTime, ShouldEqual, expectTime)
 				So(actualDur, ShouldEqual, expectDur)
@@ -414,3 +416,77 @@ func Test_Simulator_contextWithDeadline(t *testing.T) {
 		})
 	})
 }
+
+func Test_Simulator_monotonic(t *testing.T) {
+	Convey("以带有单调时钟读数的 time.Now() 新建 Simulator", t, func() {
+		now := time.Now()
+		So(now.String(), ShouldContainSubstring, "m=")
+		s := NewSimulator(now)
+		Convey("Now 不带有单调时钟读数", func() {
+			So(s.Now().String(), ShouldNotContainSubstring, "m=")
+			So(s.Now() == now.Round(0), ShouldBeTrue)
+			So(s.Now().Equal(now), ShouldBeTrue)
+		})
+		Convey("Set 的输入参数中的单调时钟读数也会被去除", func() {
+			s.Set(time.Now().Add(time.Second))
+			So(s.Now().String(), ShouldNotContainSubstring, "m=")
+		})
+		Convey("timer 发送的时间也不带有单调时钟读数", func() {
+			c := s.After(time.Second)
+			s.Add(time.Second)
+			So((<-c).String(), ShouldNotContainSubstring, "m=")
+		})
+		Convey("Since 带有单调时钟读数的时间，与 time.Since 一样", func() {
+			s.Add(time.Second)
+			So(s.Since(now), ShouldEqual, time.Second)
+		})
+	})
+}
+
+func Test_Simulator_Pending(t *testing.T) {
+	Convey("新建模拟器 s", t, func() {
+		now := time.Date(2020, 5, 20, 0, 0, 0, 0, time.UTC)
+		s := NewSimulator(now)
+		Convey("没有任务时，Pending 为空", func() {
+			So(s.Pending(), ShouldBeEmpty)
+		})
+		Convey("Pending 按照由早到晚的顺序返回到期时间", func() {
+			s.NewTimer(2 * time.Second)
+			s.NewTicker(time.Second)
+			So(s.Pending(), ShouldResemble, []time.Time{
+				now.Add(time.Second),
+				now.Add(2 * time.Second),
+			})
+			Convey("触发后的 timer 不再等待", func() {
+				s.Add(2 * time.Second)
+				So(s.Pending(), ShouldResemble, []time.Time{now.Add(3 * time.Second)})
+			})
+		})
+	})
+}
+
+func Test_Simulator_BlockUntil(t *testing.T) {
+	Convey("新建模拟器 s", t, func() {
+		s := NewSimulator(time.Now())
+		ctx := context.Background()
+		Convey("任务已经足够时，立即返回", func() {
+			s.NewTimer(time.Second)
+			So(s.BlockUntil(ctx, 1), ShouldBeNil)
+		})
+		Convey("会阻塞到其他 goroutine 开始等待", func() {
+			done := make(chan struct{})
+			go func() {
+				s.Sleep(time.Second)
+				close(done)
+			}()
+			So(s.BlockUntil(ctx, 1), ShouldBeNil)
+			s.Add(time.Second)
+			<-done
+		})
+		Convey("ctx 结束时，返回 ctx.Err()", func() {
+			ctx, cancel := context.WithCancel(ctx)
+			cancel()
+			So(s.BlockUntil(ctx, 1), ShouldNotBeNil)
+		})
+	})
+}
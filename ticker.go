@@ -16,8 +16,6 @@ type Ticker struct {
 // NewTicker returns a new Ticker containing a channel that will send the
 // current time with a period specified by the duration d.
 func (s *Simulator) NewTicker(d time.Duration) *Ticker {
-	s.Lock()
-	defer s.Unlock()
 	if d <= 0 {
 		panic("non-positive interval for NewTicker")
 	}
@@ -27,8 +25,6 @@ func (s *Simulator) NewTicker(d time.Duration) *Ticker {
 // Tick is a convenience wrapper for NewTicker providing access to the ticking
 // channel only.
 func (s *Simulator) Tick(d time.Duration) <-chan time.Time {
-	s.Lock()
-	defer s.Unlock()
 	if d <= 0 {
 		return nil
 	}
@@ -36,6 +32,13 @@ func (s *Simulator) Tick(d time.Duration) <-chan time.Time {
 }
 
 func (s *Simulator) newTicker(d time.Duration) *Ticker {
+	return s.newLocalTicker(d, nil)
+}
+
+// newLocalTicker 与 newTicker 一样，
+// 只是 C 发送的墙上时间，会先经过 localize 的转换。
+// 不需要加锁，在临界区内外都可以调用
+func (s *Simulator) newLocalTicker(d time.Duration, localize func(time.Time) time.Time) *Ticker {
 	c := make(chan time.Time, 1)
 	run := func(t *task) *task {
 		// time.Tick.C 的发送逻辑是
@@ -43,7 +46,7 @@ func (s *Simulator) newTicker(d time.Duration) *Ticker {
 		// 不能发送，就抛弃
 		// 所以，c 带有缓存，免得全部都丢弃了
 		select {
-		case c <- s.now:
+		case c <- s.localNow(localize):
 		default:
 		}
 		t.deadline = t.deadline.Add(d)
@@ -51,13 +54,14 @@ func (s *Simulator) newTicker(d time.Duration) *Ticker {
 	}
 	t := &Ticker{
 		C:    c,
-		task: newTask(s.now.Add(d), run),
+		task: newTask(s.load().mono.Add(d), run),
 	}
+	t.task.periodic = true
 	t.Stop = func() {
-		s.Lock()
-		s.heap.remove(t.task)
-		s.Unlock()
+		s.mu.Lock()
+		s.stopTask(t.task)
+		s.mu.Unlock()
 	}
-	s.accept(t.task)
+	s.register(t.task)
 	return t
 }
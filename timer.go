@@ -1,10 +1,15 @@
 package clock
 
-import "time"
+import (
+	"sync/atomic"
+	"time"
+)
 
 // Timer 替代 time.Timer.
 type Timer struct {
 	C <-chan time.Time
+	// c 与 C 是同一个 channel，用于清除过期的时间
+	c chan time.Time
 	// 当 timer != nil 的时候, Timer 代表了 real clock
 	timer *time.Timer
 	*task
@@ -23,6 +28,9 @@ type Timer struct {
 //
 // A negative or zero duration causes Sleep to return immediately.
 func (s *Simulator) Sleep(d time.Duration) {
+	if d <= 0 {
+		return
+	}
 	<-s.After(d)
 }
 
@@ -39,9 +47,7 @@ func (s *Simulator) After(d time.Duration) <-chan time.Time {
 //
 // A negative or zero duration fires the timer immediately.
 func (s *Simulator) AfterFunc(d time.Duration, f func()) *Timer {
-	s.Lock()
-	defer s.Unlock()
-	return s.newTimerFunc(s.now.Add(d), f)
+	return s.newTimerFunc(s.load().mono.Add(d), f)
 }
 
 // NewTimer creates a new Timer that will send the current time on its channel
@@ -49,44 +55,103 @@ func (s *Simulator) AfterFunc(d time.Duration, f func()) *Timer {
 //
 // A negative or zero duration fires the timer immediately.
 func (s *Simulator) NewTimer(d time.Duration) *Timer {
-	s.Lock()
-	defer s.Unlock()
-	return s.newTimerFunc(s.now.Add(d), nil)
+	return s.newTimerFunc(s.load().mono.Add(d), nil)
 }
 
 func (s *Simulator) newTimerFunc(deadline time.Time, afterFunc func()) *Timer {
+	return s.newLocalTimerFunc(deadline, afterFunc, nil)
+}
+
+// newLocalTimerFunc 与 newTimerFunc 一样，
+// 只是 C 发送的墙上时间，会先经过 localize 的转换。
+// localize 为 nil 时，发送 Simulator 的墙上时间。
+func (s *Simulator) newLocalTimerFunc(deadline time.Time, afterFunc func(), localize func(time.Time) time.Time) *Timer {
 	c := make(chan time.Time, 1)
+	site := "unknown"
+	if afterFunc != nil && atomic.LoadInt64(&s.syncTimeout) > 0 {
+		site = callerSite()
+	}
 	runTask := func(t *task) *task {
 		if afterFunc != nil {
 			// NOTICE: AfterFunc 创建的 *Timer 不会发送 current time
-			go afterFunc()
+			// 同步回调模式下，s.step 会记录回调，直到它返回
+			s.step.goCallback(afterFunc, site)
 		} else {
-			// Timer 的发送逻辑和 Tick 的不一样。
-			// 必须发送到位
-			c <- s.now
+			// 此时处于临界区内，发送不能阻塞，否则整个 Simulator 都会停下来。
+			// c 中还有没有被接收的时间的话，用新的时间替换它。
+			replace(c, s.localNow(localize))
 		}
 		return nil
 	}
 	timer := &Timer{
-		C:    c,
+		c:    c,
 		task: newTask(deadline, runTask),
 	}
-	s.accept(timer.task)
+	if afterFunc == nil {
+		// 与 time.AfterFunc 一样，AfterFunc 的 C 是 nil
+		timer.C = c
+	}
+	s.register(timer.task)
 	timer.Stop = func() bool {
-		s.Lock()
-		defer s.Unlock()
-		isActive := !timer.task.hasStopped()
-		s.heap.remove(timer.task)
-		return isActive
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		return s.stopTask(timer.task)
 	}
 	timer.Reset = func(d time.Duration) bool {
-		s.Lock()
-		defer s.Unlock()
-		isActive := !timer.hasStopped()
-		s.heap.remove(timer.task)
-		timer.deadline = s.now.Add(d)
-		s.accept(timer.task)
-		return isActive
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		timer.drain()
+		return s.resetTask(timer.task, d)
 	}
 	return timer
 }
+
+// drain 清除 t.C 中还没有被接收的时间，
+// 这样 Reset 以后，从 t.C 接收到的只会是新的到期时间。
+// 与 time.Timer 一样，Stop 不会清除，
+// 所以 if !t.Stop() { <-t.C } 的用法依然有效。
+func (t *Timer) drain() {
+	select {
+	case <-t.c:
+	default:
+	}
+}
+
+// replace 把 v 发送到 c，c 已满的话，先丢弃 c 中的旧值。
+// NOTICE: c 的容量必须为 1，并且只能在 Simulator 的临界区内发送。
+func replace(c chan time.Time, v time.Time) {
+	select {
+	case <-c:
+	default:
+	}
+	c <- v
+}
+
+// stopTask 从 heap 中移除 t，返回 t 在移除前是否还在等待触发
+// NOTICE: 务必在临界区内运行此方法
+func (s *Simulator) stopTask(t *task) bool {
+	// t 有可能还在 shard 中
+	s.drain()
+	isActive := !t.hasStopped()
+	s.heap.remove(t)
+	return isActive
+}
+
+// resetTask 让 t 在 d 后重新触发，返回 t 在重置前是否还在等待触发
+// NOTICE: 务必在临界区内运行此方法
+func (s *Simulator) resetTask(t *task, d time.Duration) bool {
+	isActive := s.stopTask(t)
+	t.deadline = s.now.Add(d)
+	s.accept(t)
+	return isActive
+}
+
+// localNow 使用 localize 转换当前的墙上时间
+// localize 为 nil 时，直接返回墙上时间
+// NOTICE: 务必在临界区内运行此方法
+func (s *Simulator) localNow(localize func(time.Time) time.Time) time.Time {
+	if localize == nil {
+		return s.wallNow()
+	}
+	return localize(s.wallNow())
+}
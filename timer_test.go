@@ -136,3 +136,28 @@ func Test_Simulator_NewTimer(t *testing.T) {
 		})
 	})
 }
+
+func Test_Simulator_Timer_nonBlocking(t *testing.T) {
+	Convey("timer 的发送不会阻塞 Simulator", t, func() {
+		now := time.Date(2020, 5, 20, 0, 0, 0, 0, time.UTC)
+		s := NewSimulator(now)
+		timer := s.NewTimer(time.Second)
+		s.Add(time.Second)
+		Convey("没有接收的 timer 被 Reset 后再次触发", func() {
+			So(timer.Reset(time.Second), ShouldBeFalse)
+			s.Add(time.Second)
+			So(s.Now(), ShouldEqual, now.Add(2*time.Second))
+			So(<-timer.C, ShouldEqual, now.Add(2*time.Second))
+			So(len(timer.C), ShouldEqual, 0)
+		})
+		Convey("Reset 会清除过期的时间", func() {
+			So(timer.Reset(time.Minute), ShouldBeFalse)
+			So(len(timer.C), ShouldEqual, 0)
+		})
+		Convey("Stop 不会清除过期的时间", func() {
+			if !timer.Stop() {
+				So(<-timer.C, ShouldEqual, now.Add(time.Second))
+			}
+		})
+	})
+}
@@ -0,0 +1,61 @@
+package truetime
+
+import (
+	"sync"
+	"time"
+)
+
+// ErrorBound 给出了时钟在 now 时刻的误差上限
+type ErrorBound interface {
+	Epsilon(now time.Time) time.Duration
+}
+
+// fixed 的误差上限是固定的
+type fixed time.Duration
+
+// FixedBound 返回固定为 epsilon 的误差上限
+func FixedBound(epsilon time.Duration) ErrorBound {
+	return fixed(epsilon)
+}
+
+func (f fixed) Epsilon(time.Time) time.Duration {
+	return time.Duration(f)
+}
+
+// DriftBound 模拟了 TrueTime 中误差上限的变化规律：
+// 每次与时间服务器同步后，误差上限回到同步时测得的 base，
+// 之后按照最大漂移率 ppm 线性增长，直到下一次同步。
+type DriftBound struct {
+	mu       sync.Mutex
+	base     time.Duration
+	ppm      float64
+	lastSync time.Time
+}
+
+// NewDriftBound 返回在 lastSync 时刻同步过，误差为 base，
+// 之后按照 ppm 的漂移率增长的 *DriftBound
+func NewDriftBound(base time.Duration, ppm float64, lastSync time.Time) *DriftBound {
+	return &DriftBound{
+		base:     base,
+		ppm:      ppm,
+		lastSync: lastSync,
+	}
+}
+
+// Sync 记录了一次在 now 时刻，误差为 base 的同步
+func (b *DriftBound) Sync(now time.Time, base time.Duration) {
+	b.mu.Lock()
+	b.base, b.lastSync = base, now
+	b.mu.Unlock()
+}
+
+// Epsilon 实现了 ErrorBound 接口
+func (b *DriftBound) Epsilon(now time.Time) time.Duration {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	elapsed := now.Sub(b.lastSync)
+	if elapsed < 0 {
+		elapsed = 0
+	}
+	return b.base + time.Duration(float64(elapsed)*b.ppm/1e6)
+}
@@ -0,0 +1,36 @@
+package truetime
+
+import (
+	"testing"
+	"time"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func Test_FixedBound(t *testing.T) {
+	Convey("FixedBound 的误差上限不会改变", t, func() {
+		b := FixedBound(time.Millisecond)
+		So(b.Epsilon(time.Now()), ShouldEqual, time.Millisecond)
+	})
+}
+
+func Test_DriftBound(t *testing.T) {
+	Convey("同步后的 DriftBound", t, func() {
+		now := time.Date(2020, 5, 20, 0, 0, 0, 0, time.UTC)
+		b := NewDriftBound(time.Millisecond, 200, now)
+		Convey("同步时，误差上限是 base", func() {
+			So(b.Epsilon(now), ShouldEqual, time.Millisecond)
+		})
+		Convey("误差上限随时间线性增长", func() {
+			So(b.Epsilon(now.Add(30*time.Second)), ShouldEqual, 7*time.Millisecond)
+		})
+		Convey("早于同步的时刻，误差上限是 base", func() {
+			So(b.Epsilon(now.Add(-time.Second)), ShouldEqual, time.Millisecond)
+		})
+		Convey("再次同步后，误差上限回到新的 base", func() {
+			later := now.Add(time.Minute)
+			b.Sync(later, 2*time.Millisecond)
+			So(b.Epsilon(later), ShouldEqual, 2*time.Millisecond)
+		})
+	})
+}
@@ -0,0 +1,70 @@
+// Package truetime 在 clock.Clock 的基础上，提供了 TrueTime 风格的时间区间。
+//
+// 真实的时间一定在 [Earliest, Latest] 之中。
+// 使用 *clock.Simulator 作为底层时钟的话，可以精确地测试 commit-wait 的逻辑。
+package truetime
+
+import (
+	"context"
+	"time"
+
+	"github.com/jujili/clock"
+)
+
+// TrueTime 是带有误差区间的时钟
+type TrueTime struct {
+	clock clock.Clock
+	bound ErrorBound
+}
+
+// New 返回以 c 为底层时钟，以 bound 为误差上限的 *TrueTime
+func New(c clock.Clock, bound ErrorBound) *TrueTime {
+	return &TrueTime{
+		clock: c,
+		bound: bound,
+	}
+}
+
+// Clock 返回底层时钟
+func (tt *TrueTime) Clock() clock.Clock {
+	return tt.clock
+}
+
+// NowInterval 返回当前时间的区间，真实的时间一定在 [earliest, latest] 之中
+func (tt *TrueTime) NowInterval() (earliest, latest time.Time) {
+	now := tt.clock.Now()
+	eps := tt.bound.Epsilon(now)
+	return now.Add(-eps), now.Add(eps)
+}
+
+// After 判断 t 是否肯定已经过去了
+func (tt *TrueTime) After(t time.Time) bool {
+	earliest, _ := tt.NowInterval()
+	return earliest.After(t)
+}
+
+// Before 判断 t 是否肯定还没有到来
+func (tt *TrueTime) Before(t time.Time) bool {
+	_, latest := tt.NowInterval()
+	return latest.Before(t)
+}
+
+// WaitUntilAfter 使用底层时钟的 timer 等待，直到 After(t) 为 true。
+// 这就是 Spanner 中的 commit-wait。
+// ctx 先结束的话，返回 ctx.Err()
+func (tt *TrueTime) WaitUntilAfter(ctx context.Context, t time.Time) error {
+	for {
+		earliest, _ := tt.NowInterval()
+		if earliest.After(t) {
+			return nil
+		}
+		// 误差上限有可能在等待的过程中改变，所以需要循环检查
+		timer := tt.clock.NewTimer(t.Sub(earliest) + 1)
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		}
+	}
+}
@@ -0,0 +1,86 @@
+package truetime
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/jujili/clock"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func Test_TrueTime(t *testing.T) {
+	Convey("误差为 7ms 的 TrueTime", t, func() {
+		now := time.Date(2020, 5, 20, 0, 0, 0, 0, time.UTC)
+		eps := 7 * time.Millisecond
+		s := clock.NewSimulator(now)
+		tt := New(s, FixedBound(eps))
+		So(tt.Clock(), ShouldEqual, s)
+		Convey("NowInterval 是 [now-eps, now+eps]", func() {
+			earliest, latest := tt.NowInterval()
+			So(earliest, ShouldEqual, now.Add(-eps))
+			So(latest, ShouldEqual, now.Add(eps))
+		})
+		Convey("误差范围内的时间，既不肯定过去，也不肯定未来", func() {
+			So(tt.After(now), ShouldBeFalse)
+			So(tt.Before(now), ShouldBeFalse)
+		})
+		Convey("误差范围外的时间", func() {
+			So(tt.After(now.Add(-eps-1)), ShouldBeTrue)
+			So(tt.Before(now.Add(eps+1)), ShouldBeTrue)
+		})
+	})
+}
+
+func Test_TrueTime_WaitUntilAfter(t *testing.T) {
+	Convey("在 Simulator 上 commit-wait", t, func() {
+		now := time.Date(2020, 5, 20, 0, 0, 0, 0, time.UTC)
+		eps := 7 * time.Millisecond
+		s := clock.NewSimulator(now)
+		tt := New(s, FixedBound(eps))
+		ctx := context.Background()
+		Convey("已经过去的时间，不需要等待", func() {
+			So(tt.WaitUntilAfter(ctx, now.Add(-time.Second)), ShouldBeNil)
+		})
+		Convey("commit 时间戳取 latest，需要等待 2*eps", func() {
+			_, commit := tt.NowInterval()
+			done := make(chan error)
+			go func() { done <- tt.WaitUntilAfter(ctx, commit) }()
+			// NOTICE: time.Sleep 是为了让 WaitUntilAfter 有机会创建 timer
+			time.Sleep(10 * time.Millisecond)
+			s.Move()
+			So(<-done, ShouldBeNil)
+			So(s.Now(), ShouldEqual, now.Add(2*eps+1))
+			So(tt.After(commit), ShouldBeTrue)
+		})
+		Convey("误差增长时，会继续等待", func() {
+			bound := NewDriftBound(eps, 1e5, now)
+			tt := New(s, bound)
+			commit := now.Add(eps)
+			done := make(chan error)
+			go func() { done <- tt.WaitUntilAfter(ctx, commit) }()
+			var err error
+		WAIT:
+			for {
+				select {
+				case err = <-done:
+					break WAIT
+				case <-time.After(10 * time.Millisecond):
+					s.Move()
+				}
+			}
+			So(err, ShouldBeNil)
+			So(tt.After(commit), ShouldBeTrue)
+		})
+		Convey("ctx 结束时，返回 ctx.Err()", func() {
+			ctx, cancel := clock.ContextWithTimeout(clock.Set(ctx, s), eps)
+			defer cancel()
+			done := make(chan error)
+			go func() { done <- tt.WaitUntilAfter(ctx, now.Add(time.Hour)) }()
+			time.Sleep(10 * time.Millisecond)
+			s.Move()
+			So(errors.Is(<-done, context.DeadlineExceeded), ShouldBeTrue)
+		})
+	})
+}
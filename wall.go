@@ -0,0 +1,157 @@
+package clock
+
+import (
+	"math"
+	"time"
+)
+
+// Simulator 内部有两条时间线：
+//   - 单调时间线 s.now，只会不断变大，所有的 timer 都在这条时间线上触发。
+//   - 墙上时间线，是单调时间加上偏移，Now 返回的就是墙上时间。
+//
+// 偏移默认为 0，此时两条时间线完全一样。
+// 使用 StepWall 和 SlewWall 可以模拟 NTP 对机器时钟的跳变和微调。
+//
+// 偏移由一系列的 wallSegment 描述，
+// 每一段从单调时间 start 开始，到下一段的 start 结束。
+// 段内的偏移为
+//
+//	offset + (min(m, end) - start) * rate
+//
+// rate 不为 0 时，表示这一段的开头正在微调，到 end 时微调结束。
+type wallSegment struct {
+	start  time.Time
+	offset time.Duration
+	rate   float64
+	end    time.Time
+}
+
+// offsetAt 返回 seg 在单调时间 m 的偏移
+func (seg wallSegment) offsetAt(m time.Time) time.Duration {
+	if seg.rate == 0 {
+		return seg.offset
+	}
+	if m.After(seg.end) {
+		m = seg.end
+	}
+	return seg.offset + time.Duration(math.Round(float64(m.Sub(seg.start))*seg.rate))
+}
+
+// monoOf 在 seg 内求解 m + offsetAt(m) = wall
+// 由于 rate > -1，墙上时间在段内是严格递增的，所以解是唯一的。
+func (seg wallSegment) monoOf(wall time.Time) time.Time {
+	// 微调结束后的部分，偏移固定不变
+	if seg.rate == 0 || !wall.Before(seg.end.Add(seg.offsetAt(seg.end))) {
+		return wall.Add(-seg.offsetAt(seg.end))
+	}
+	elapsed := float64(wall.Sub(seg.start.Add(seg.offset))) / (1 + seg.rate)
+	return seg.start.Add(time.Duration(math.Round(elapsed)))
+}
+
+// NOTICE: 以下方法，务必在 s 的临界区内运行
+
+// segmentAt 返回单调时间 m 所在的段
+func (s *Simulator) segmentAt(m time.Time) wallSegment {
+	for i := len(s.wall) - 1; i > 0; i-- {
+		if !m.Before(s.wall[i].start) {
+			return s.wall[i]
+		}
+	}
+	if len(s.wall) == 0 {
+		return wallSegment{}
+	}
+	return s.wall[0]
+}
+
+func (s *Simulator) wallAt(m time.Time) time.Time {
+	return m.Add(s.segmentAt(m).offsetAt(m))
+}
+
+func (s *Simulator) wallNow() time.Time {
+	return s.wallAt(s.now)
+}
+
+// monoOf 返回墙上时间 wall 对应的单调时间，
+// 用于模拟 time 标准库中，带有单调时钟读数的时间运算。
+//
+// 墙上时间被向后跳变过的话，同一个墙上时间可能对应多个单调时间，
+// 此时选择最近的那一个。
+// wall 不是 Simulator 产生过的墙上时间的话（比如：未来的时间，或者跳变跨过的时间），
+// 就按照当前的偏移换算。
+func (s *Simulator) monoOf(wall time.Time) time.Time {
+	limit := s.now
+	for i := len(s.wall) - 1; i >= 0; i-- {
+		seg := s.wall[i]
+		first := seg.start.Add(seg.offsetAt(seg.start))
+		last := limit.Add(seg.offsetAt(limit))
+		inside := !wall.Before(first) && wall.Before(last)
+		if i == len(s.wall)-1 {
+			inside = !wall.Before(first) && !wall.After(last)
+		}
+		if inside {
+			return seg.monoOf(wall)
+		}
+		limit = seg.start
+	}
+	return wall.Add(-s.segmentAt(s.now).offsetAt(s.now))
+}
+
+// startSegment 从 s.now 开始新的一段，
+// 正在进行的微调会被取消
+func (s *Simulator) startSegment(seg wallSegment) {
+	last := len(s.wall) - 1
+	if s.wall[last].start.Equal(s.now) {
+		s.wall[last] = seg
+	} else {
+		s.wall = append(s.wall, seg)
+	}
+	s.publish()
+}
+
+// WallOffset 返回墙上时间与单调时间的差
+func (s *Simulator) WallOffset() time.Duration {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.segmentAt(s.now).offsetAt(s.now)
+}
+
+// StepWall 让墙上时间立即跳变 d，d 可以为负数。
+// 单调时间线和所有的 timer 都不受影响。
+// 正在进行的微调会被取消。
+func (s *Simulator) StepWall(d time.Duration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.startSegment(wallSegment{
+		start:  s.now,
+		offset: s.segmentAt(s.now).offsetAt(s.now) + d,
+	})
+}
+
+// SlewWall 以 ppm 的速率逐渐地把墙上时间调整 d，
+// 就像 NTP 微调机器时钟一样，墙上时间不会逆转。
+// 微调期间，墙上时间的流逝速度是单调时间的 1±ppm/1e6 倍。
+// ppm 必须在 (0, 1e6) 之间，否则会 panic
+func (s *Simulator) SlewWall(d time.Duration, ppm float64) {
+	if ppm <= 0 || ppm >= 1e6 {
+		panic("slew rate must be in (0, 1e6) ppm")
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	rate := ppm / 1e6
+	if d < 0 {
+		rate = -rate
+	}
+	seg := wallSegment{
+		start:  s.now,
+		offset: s.segmentAt(s.now).offsetAt(s.now),
+		rate:   rate,
+	}
+	seg.end = s.now.Add(time.Duration(math.Round(float64(d) / rate)))
+	s.startSegment(seg)
+}
+
+// MonotonicNow 返回单调时间线上的当前时间，
+// 在没有跳变和微调的时候，与 Now 相同。
+func (s *Simulator) MonotonicNow() time.Time {
+	return s.load().mono
+}
@@ -0,0 +1,113 @@
+package clock
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func Test_Simulator_StepWall(t *testing.T) {
+	Convey("新建一个 Simulator s", t, func() {
+		now := time.Date(2020, 5, 20, 0, 0, 0, 0, time.UTC)
+		s := NewSimulator(now)
+		Convey("没有调整时，墙上时间与单调时间一致", func() {
+			So(s.Now(), ShouldEqual, s.MonotonicNow())
+			So(s.WallOffset(), ShouldEqual, 0)
+		})
+		Convey("墙上时间向后跳变 1 分钟", func() {
+			before := s.Now()
+			s.Add(time.Second)
+			s.StepWall(-time.Minute)
+			after := s.Now()
+			Convey("Now 会逆转", func() {
+				So(after, ShouldEqual, now.Add(time.Second-time.Minute))
+				So(s.MonotonicNow(), ShouldEqual, now.Add(time.Second))
+				So(s.WallOffset(), ShouldEqual, -time.Minute)
+			})
+			Convey("Since 不受跳变的影响", func() {
+				So(s.Since(before), ShouldEqual, time.Second)
+				So(s.Since(after), ShouldEqual, 0)
+			})
+			Convey("逆转前后都出现过的墙上时间，以最近的为准", func() {
+				s.Add(time.Minute)
+				// before 在跳变后又出现了一次
+				So(s.Since(before), ShouldEqual, time.Second)
+			})
+			Convey("Until 按照当前的偏移换算未来的时间", func() {
+				So(s.Until(after.Add(time.Hour)), ShouldEqual, time.Hour)
+			})
+		})
+		Convey("墙上时间向前跳变后，timer 依然按照单调时间触发", func() {
+			timer := s.NewTimer(time.Second)
+			s.StepWall(time.Hour)
+			So(s.Now(), ShouldEqual, now.Add(time.Hour))
+			s.Add(time.Second)
+			Convey("C 中发送的是墙上时间", func() {
+				So(<-timer.C, ShouldEqual, now.Add(time.Hour+time.Second))
+			})
+		})
+	})
+}
+
+func Test_Simulator_SlewWall(t *testing.T) {
+	Convey("新建一个 Simulator s", t, func() {
+		now := time.Date(2020, 5, 20, 0, 0, 0, 0, time.UTC)
+		s := NewSimulator(now)
+		Convey("以 500ppm 的速率把墙上时间调慢 1ms", func() {
+			s.SlewWall(-time.Millisecond, 500)
+			Convey("微调期间，墙上时间走得慢一些，但不会逆转", func() {
+				s.Add(time.Second)
+				So(s.WallOffset(), ShouldEqual, -500*time.Microsecond)
+				So(s.Now(), ShouldEqual, now.Add(time.Second-500*time.Microsecond))
+			})
+			Convey("微调结束后，偏移不再改变", func() {
+				s.Add(2 * time.Second)
+				So(s.WallOffset(), ShouldEqual, -time.Millisecond)
+				s.Add(time.Hour)
+				So(s.WallOffset(), ShouldEqual, -time.Millisecond)
+			})
+			Convey("微调期间产生的时间，Since 也是准确的", func() {
+				s.Add(time.Second)
+				during := s.Now()
+				s.Add(3 * time.Second)
+				So(s.Since(during), ShouldEqual, 3*time.Second)
+			})
+			Convey("跳变会取消正在进行的微调", func() {
+				s.Add(time.Second)
+				s.StepWall(0)
+				s.Add(time.Hour)
+				So(s.WallOffset(), ShouldEqual, -500*time.Microsecond)
+			})
+		})
+		Convey("非法的微调速率会 panic", func() {
+			So(func() { s.SlewWall(time.Second, 0) }, ShouldPanic)
+			So(func() { s.SlewWall(time.Second, 1e6) }, ShouldPanic)
+		})
+	})
+}
+
+func Test_Simulator_wall_context(t *testing.T) {
+	Convey("墙上时间跳变后", t, func() {
+		now := time.Date(2020, 5, 20, 0, 0, 0, 0, time.UTC)
+		s := NewSimulator(now)
+		s.StepWall(time.Hour)
+		Convey("ContextWithTimeout 的 Deadline 是墙上时间", func() {
+			ctx, cancel := s.ContextWithTimeout(context.Background(), time.Second)
+			defer cancel()
+			deadline, _ := ctx.Deadline()
+			So(deadline, ShouldEqual, now.Add(time.Hour+time.Second))
+			s.Add(time.Second)
+			<-ctx.Done()
+		})
+		Convey("ContextWithDeadline 按照墙上时间到期", func() {
+			ctx, cancel := s.ContextWithDeadline(context.Background(), s.Now().Add(time.Second))
+			defer cancel()
+			s.Add(time.Second - 1)
+			So(ctx.Err(), ShouldBeNil)
+			s.Add(1)
+			<-ctx.Done()
+		})
+	})
+}
@@ -0,0 +1,41 @@
+package clock
+
+import (
+	"fmt"
+	"time"
+)
+
+// onDeadlock 在 watchdog 超时的时候被调用，测试时可以替换
+var onDeadlock = func(msg string) {
+	panic(msg)
+}
+
+// SetWatchdog 设置触发单个任务的最长时间（真实时间），d <= 0 表示不检查。
+// NewSimulator 创建的 Simulator 默认不检查。
+//
+// 任务是在 Simulator 的临界区内触发的，
+// 如果某个任务被阻塞，所有的 Simulator 方法都会被阻塞，程序就会悄无声息地停下来。
+// 超过 d 以后，Simulator 会 panic 并给出任务的到期时间，而不是一直挂起。
+//
+// NOTICE: panic 发生在 time.AfterFunc 的 goroutine 中，无法 recover，会结束整个程序，
+// 在断点调试，或者 -race 下运行得很慢的时候，也有可能超时，所以 d 要留有余量。
+// 每触发一个任务，都会新建一个真实的 timer，会让 Simulator 变慢。
+func (s *Simulator) SetWatchdog(d time.Duration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.watchdog = d
+}
+
+// watch 在触发 t 之前调用，返回的函数在 t 触发完毕后调用
+// NOTICE: 务必在临界区内运行此方法
+func (s *Simulator) watch(t *task) (stop func()) {
+	if s.watchdog <= 0 {
+		return func() {}
+	}
+	d, deadline := s.watchdog, s.wallAt(t.deadline)
+	timer := time.AfterFunc(d, func() {
+		onDeadlock(fmt.Sprintf("clock: Simulator 触发 %s 到期的任务时，阻塞超过了 %s，可能发生了死锁",
+			deadline.Format(time.RFC3339Nano), d))
+	})
+	return func() { timer.Stop() }
+}
@@ -0,0 +1,47 @@
+package clock
+
+import (
+	"testing"
+	"time"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func Test_Simulator_SetWatchdog(t *testing.T) {
+	Convey("Simulator 的 watchdog", t, func() {
+		now := time.Date(2020, 5, 20, 0, 0, 0, 0, time.UTC)
+		s := NewSimulator(now)
+		So(s.watchdog, ShouldEqual, 0)
+		msgs := make(chan string, 1)
+		old := onDeadlock
+		onDeadlock = func(msg string) { msgs <- msg }
+		defer func() { onDeadlock = old }()
+		block := make(chan struct{})
+		s.mu.Lock()
+		s.accept(newTask(now.Add(time.Second), func(t *task) *task {
+			<-block
+			return nil
+		}))
+		s.mu.Unlock()
+		Convey("任务被阻塞时，报告死锁", func() {
+			s.SetWatchdog(10 * time.Millisecond)
+			go s.Add(time.Second)
+			msg := <-msgs
+			close(block)
+			So(msg, ShouldContainSubstring, "阻塞超过了 10ms")
+			So(msg, ShouldContainSubstring, "2020-05-20T00:00:01Z")
+		})
+		Convey("d <= 0 时，不再检查", func() {
+			s.SetWatchdog(0)
+			done := make(chan struct{})
+			go func() {
+				s.Add(time.Second)
+				close(done)
+			}()
+			time.Sleep(20 * time.Millisecond)
+			So(len(msgs), ShouldEqual, 0)
+			close(block)
+			<-done
+		})
+	})
+}